@@ -0,0 +1,278 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"strconv"
+	"strings"
+)
+
+// AdminServerConfig holds configuration for a private admin/ops listener that
+// serves health, readiness, metrics and profiling endpoints away from the
+// public server port. It reuses ServerConfig for addressing, timeouts, TLS and
+// listeners, so Listen, Run and Serve work the same way.
+type AdminServerConfig struct {
+	ServerConfig `mapstructure:",squash"`
+
+	HealthEnabled    bool   `mapstructure:"health_enabled"`
+	HealthPath       string `mapstructure:"health_path"`
+	ReadinessEnabled bool   `mapstructure:"readiness_enabled"`
+	ReadinessPath    string `mapstructure:"readiness_path"`
+	PprofEnabled     bool   `mapstructure:"pprof_enabled"`
+
+	// public is the public server configuration loaded alongside, checked by
+	// Validate for address collisions
+	public *ServerConfig
+}
+
+// pprofPath is where pprof handlers are mounted. It is fixed because
+// pprof.Index links to profiles under this prefix.
+const pprofPath = "/debug/pprof/"
+
+// defaultAdminServerPort is applied when admin_server.port is unset
+const defaultAdminServerPort = 9090
+
+// adminServerKeyPrefix prefixes admin server keys in validation errors
+const adminServerKeyPrefix = "admin_server"
+
+// AdminServerConfigFromViper creates an AdminServerConfig from a Standard
+// config loader.
+//
+// Environment variable mappings are the same as ServerConfigFromViper with the
+// ADMIN_SERVER_ prefix, plus:
+//   - ADMIN_SERVER_HOST -> host (default: 127.0.0.1)
+//   - ADMIN_SERVER_PORT -> port (default: 9090)
+//   - ADMIN_SERVER_HEALTH_ENABLED -> health_enabled (default: false)
+//   - ADMIN_SERVER_HEALTH_PATH -> health_path (default: /healthz)
+//   - ADMIN_SERVER_READINESS_ENABLED -> readiness_enabled (default: false)
+//   - ADMIN_SERVER_READINESS_PATH -> readiness_path (default: /readyz)
+//   - ADMIN_SERVER_PPROF_ENABLED -> pprof_enabled (default: false, served at /debug/pprof/)
+//
+// The public server settings (see ServerConfigFromViper) are loaded as well,
+// so that Validate rejects an admin address that collides with them.
+func AdminServerConfigFromViper(s *Standard) AdminServerConfig {
+	// Bind environment variables
+	bindServerEnv(s, "admin_server.", "ADMIN_SERVER_")
+	_ = s.BindEnv("admin_server.health_enabled", "ADMIN_SERVER_HEALTH_ENABLED")
+	_ = s.BindEnv("admin_server.health_path", "ADMIN_SERVER_HEALTH_PATH")
+	_ = s.BindEnv("admin_server.readiness_enabled", "ADMIN_SERVER_READINESS_ENABLED")
+	_ = s.BindEnv("admin_server.readiness_path", "ADMIN_SERVER_READINESS_PATH")
+	_ = s.BindEnv("admin_server.pprof_enabled", "ADMIN_SERVER_PPROF_ENABLED")
+
+	config := AdminServerConfig{
+		ServerConfig:     loadServerConfig(s, "admin_server.", defaultAdminServerPort),
+		HealthEnabled:    s.GetBool("admin_server.health_enabled"),
+		HealthPath:       s.GetString("admin_server.health_path"),
+		ReadinessEnabled: s.GetBool("admin_server.readiness_enabled"),
+		ReadinessPath:    s.GetString("admin_server.readiness_path"),
+		PprofEnabled:     s.GetBool("admin_server.pprof_enabled"),
+	}
+	public := ServerConfigFromViper(s)
+	config.public = &public
+
+	// Apply defaults
+	config.setDefaults()
+
+	return config
+}
+
+// setDefaults sets default values for optional fields
+func (c *AdminServerConfig) setDefaults() {
+	// Bind loopback only unless a host is configured explicitly
+	if c.Host == "" {
+		c.Host = "127.0.0.1"
+	}
+	if c.HealthPath == "" {
+		c.HealthPath = "/healthz"
+	}
+	if c.ReadinessPath == "" {
+		c.ReadinessPath = "/readyz"
+	}
+	if c.keyPrefix == "" {
+		c.keyPrefix = adminServerKeyPrefix
+	}
+	c.ServerConfig.setDefaults()
+}
+
+// Validate validates the admin server configuration. For configs loaded
+// with AdminServerConfigFromViper it also checks that the admin address does
+// not collide with the public server's; use ValidateAgainst for others.
+func (c *AdminServerConfig) Validate() error {
+	if err := c.validate(); err != nil {
+		return err
+	}
+	if c.public != nil {
+		return c.validateCollision(*c.public)
+	}
+	return nil
+}
+
+// ValidateAgainst validates the admin server configuration and checks that it
+// does not listen on the same address as the public server.
+func (c *AdminServerConfig) ValidateAgainst(public ServerConfig) error {
+	if err := c.validate(); err != nil {
+		return err
+	}
+	return c.validateCollision(public)
+}
+
+// server returns the embedded ServerConfig with the admin key prefix, without
+// modifying c
+func (c *AdminServerConfig) server() ServerConfig {
+	server := c.ServerConfig
+	if server.keyPrefix == "" {
+		server.keyPrefix = adminServerKeyPrefix
+	}
+	return server
+}
+
+// key returns the fully qualified config key for field
+func (c *AdminServerConfig) key(field string) string {
+	server := c.server()
+	return server.key(field)
+}
+
+// validate validates the admin server settings on their own
+func (c *AdminServerConfig) validate() error {
+	server := c.server()
+	if err := server.Validate(); err != nil {
+		return err
+	}
+	if c.HealthEnabled {
+		if err := validateHandlerPath(c.key("health_path"), c.HealthPath); err != nil {
+			return err
+		}
+	}
+	if c.ReadinessEnabled {
+		if err := validateHandlerPath(c.key("readiness_path"), c.ReadinessPath); err != nil {
+			return err
+		}
+	}
+	if c.HealthEnabled && c.ReadinessEnabled && c.HealthPath == c.ReadinessPath {
+		return fmt.Errorf("%s and %s must differ, both are %q",
+			c.key("health_path"), c.key("readiness_path"), c.HealthPath)
+	}
+
+	return nil
+}
+
+// validateCollision checks that the admin server does not listen on the same
+// address as public
+func (c *AdminServerConfig) validateCollision(public ServerConfig) error {
+	if listenersCollide(c.ServerConfig, public) {
+		return fmt.Errorf("%s address %s collides with %s address %s",
+			c.key("listen"), c.describeListen(), public.key("listen"), public.describeListen())
+	}
+	return nil
+}
+
+// validateHandlerPath validates that path is an absolute URL path outside the
+// pprof prefix
+func validateHandlerPath(field, path string) error {
+	if !strings.HasPrefix(path, "/") {
+		return fmt.Errorf("%s must start with /, got %q", field, path)
+	}
+	if strings.HasPrefix(path, pprofPath) {
+		return fmt.Errorf("%s must not be under %s, got %q", field, pprofPath, path)
+	}
+	return nil
+}
+
+// NewMux returns an *http.ServeMux with the enabled admin handlers mounted:
+//
+//   - HealthPath: always responds 200 OK while the process is serving
+//   - ReadinessPath: responds 200 OK when ready returns nil, or 503 Service
+//     Unavailable with the error text otherwise. A nil ready is always ready.
+//   - /debug/pprof/: the net/http/pprof handlers
+//
+// Callers can mount further handlers, such as /metrics, on the returned mux.
+func (c *AdminServerConfig) NewMux(ready func(context.Context) error) *http.ServeMux {
+	mux := http.NewServeMux()
+
+	if c.HealthEnabled {
+		mux.HandleFunc(c.HealthPath, func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			_, _ = w.Write([]byte("ok\n"))
+		})
+	}
+
+	if c.ReadinessEnabled {
+		mux.HandleFunc(c.ReadinessPath, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			if ready != nil {
+				if err := ready(r.Context()); err != nil {
+					http.Error(w, "not ready: "+err.Error(), http.StatusServiceUnavailable)
+					return
+				}
+			}
+			_, _ = w.Write([]byte("ready\n"))
+		})
+	}
+
+	if c.PprofEnabled {
+		mux.HandleFunc(pprofPath, pprof.Index)
+		mux.HandleFunc(pprofPath+"cmdline", pprof.Cmdline)
+		mux.HandleFunc(pprofPath+"profile", pprof.Profile)
+		mux.HandleFunc(pprofPath+"symbol", pprof.Symbol)
+		mux.HandleFunc(pprofPath+"trace", pprof.Trace)
+	}
+
+	return mux
+}
+
+// describeListen returns the configured listen address for error messages
+func (c *ServerConfig) describeListen() string {
+	if c.ListenAddr != "" {
+		return c.ListenAddr
+	}
+	return c.Address()
+}
+
+// listenersCollide reports whether a and b are configured to listen on the
+// same address. Ephemeral TCP ports never collide; wildcard hosts collide with
+// any host on the same port.
+func listenersCollide(a, b ServerConfig) bool {
+	aScheme, aAddr := a.listenTarget()
+	bScheme, bAddr := b.listenTarget()
+	if aScheme != bScheme {
+		return false
+	}
+	if aScheme != listenSchemeTCP {
+		return aAddr == bAddr
+	}
+
+	aHost, aPort, aErr := net.SplitHostPort(aAddr)
+	bHost, bPort, bErr := net.SplitHostPort(bAddr)
+	if aErr != nil || bErr != nil {
+		return false
+	}
+	if p, err := strconv.Atoi(aPort); err != nil || p == 0 || aPort != bPort {
+		return false
+	}
+	aHost, bHost = normalizeListenHost(aHost), normalizeListenHost(bHost)
+	return aHost == bHost || aHost == "" || bHost == ""
+}
+
+// listenTarget returns the scheme and address the server listens on
+func (c *ServerConfig) listenTarget() (string, string) {
+	for _, scheme := range []string{listenSchemeTCP, listenSchemeUnix, listenSchemeFD} {
+		if strings.HasPrefix(c.ListenAddr, scheme) {
+			return scheme, strings.TrimPrefix(c.ListenAddr, scheme)
+		}
+	}
+	return listenSchemeTCP, c.Address()
+}
+
+// normalizeListenHost maps wildcard hosts to "" and localhost to 127.0.0.1
+func normalizeListenHost(host string) string {
+	switch host {
+	case "", "0.0.0.0", "::":
+		return ""
+	case "localhost":
+		return "127.0.0.1"
+	}
+	return host
+}
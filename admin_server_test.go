@@ -0,0 +1,278 @@
+package config_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	config "github.com/JohnPlummer/jp-go-config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminServerConfigFromViper(t *testing.T) {
+	t.Run("uses loopback defaults when no config provided", func(t *testing.T) {
+		std, err := config.NewStandard()
+		require.NoError(t, err)
+
+		cfg := config.AdminServerConfigFromViper(std)
+
+		assert.Equal(t, "127.0.0.1", cfg.Host)
+		assert.Equal(t, 9090, cfg.Port)
+		assert.Equal(t, 5*time.Second, cfg.ReadHeaderTimeout)
+		assert.False(t, cfg.HealthEnabled)
+		assert.False(t, cfg.ReadinessEnabled)
+		assert.False(t, cfg.PprofEnabled)
+		assert.Equal(t, "/healthz", cfg.HealthPath)
+		assert.Equal(t, "/readyz", cfg.ReadinessPath)
+		require.NoError(t, cfg.Validate())
+	})
+
+	t.Run("loads from environment variables", func(t *testing.T) {
+		os.Setenv("ADMIN_SERVER_HOST", "0.0.0.0")
+		os.Setenv("ADMIN_SERVER_PORT", "9100")
+		os.Setenv("ADMIN_SERVER_WRITE_TIMEOUT", "60s")
+		os.Setenv("ADMIN_SERVER_HEALTH_ENABLED", "true")
+		os.Setenv("ADMIN_SERVER_HEALTH_PATH", "/livez")
+		os.Setenv("ADMIN_SERVER_READINESS_ENABLED", "true")
+		os.Setenv("ADMIN_SERVER_PPROF_ENABLED", "true")
+		defer func() {
+			os.Unsetenv("ADMIN_SERVER_HOST")
+			os.Unsetenv("ADMIN_SERVER_PORT")
+			os.Unsetenv("ADMIN_SERVER_WRITE_TIMEOUT")
+			os.Unsetenv("ADMIN_SERVER_HEALTH_ENABLED")
+			os.Unsetenv("ADMIN_SERVER_HEALTH_PATH")
+			os.Unsetenv("ADMIN_SERVER_READINESS_ENABLED")
+			os.Unsetenv("ADMIN_SERVER_PPROF_ENABLED")
+		}()
+
+		std, err := config.NewStandard()
+		require.NoError(t, err)
+
+		cfg := config.AdminServerConfigFromViper(std)
+
+		assert.Equal(t, "0.0.0.0", cfg.Host)
+		assert.Equal(t, 9100, cfg.Port)
+		assert.Equal(t, 60*time.Second, cfg.WriteTimeout)
+		assert.True(t, cfg.HealthEnabled)
+		assert.Equal(t, "/livez", cfg.HealthPath)
+		assert.True(t, cfg.ReadinessEnabled)
+		assert.True(t, cfg.PprofEnabled)
+	})
+
+	t.Run("does not read public server settings", func(t *testing.T) {
+		os.Setenv("SERVER_PORT", "9000")
+		defer os.Unsetenv("SERVER_PORT")
+
+		std, err := config.NewStandard()
+		require.NoError(t, err)
+
+		cfg := config.AdminServerConfigFromViper(std)
+
+		assert.Equal(t, 9090, cfg.Port)
+	})
+
+	t.Run("validate rejects a collision with the public server", func(t *testing.T) {
+		os.Setenv("SERVER_HOST", "0.0.0.0")
+		os.Setenv("SERVER_PORT", "9090")
+		defer os.Unsetenv("SERVER_HOST")
+		defer os.Unsetenv("SERVER_PORT")
+
+		std, err := config.NewStandard()
+		require.NoError(t, err)
+
+		cfg := config.AdminServerConfigFromViper(std)
+
+		err = cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "admin_server.listen address 127.0.0.1:9090 collides with server.listen")
+	})
+}
+
+func TestAdminServerConfig_ValidateDoesNotModify(t *testing.T) {
+	cfg := validAdminServerConfig()
+	cfg.Port = -1
+	before := cfg
+
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "admin_server.port")
+	assert.Equal(t, before, cfg)
+}
+
+func validAdminServerConfig() config.AdminServerConfig {
+	return config.AdminServerConfig{
+		ServerConfig: config.ServerConfig{
+			Host:              "127.0.0.1",
+			Port:              9090,
+			ReadTimeout:       15 * time.Second,
+			ReadHeaderTimeout: 5 * time.Second,
+			WriteTimeout:      15 * time.Second,
+			IdleTimeout:       60 * time.Second,
+		},
+		HealthPath:    "/healthz",
+		ReadinessPath: "/readyz",
+	}
+}
+
+func TestAdminServerConfig_Validate(t *testing.T) {
+	t.Run("valid config passes", func(t *testing.T) {
+		cfg := validAdminServerConfig()
+		require.NoError(t, cfg.Validate())
+	})
+
+	t.Run("errors name the admin_server section", func(t *testing.T) {
+		cfg := validAdminServerConfig()
+		cfg.Port = 70000
+
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "admin_server.port")
+	})
+
+	t.Run("relative health path fails", func(t *testing.T) {
+		cfg := validAdminServerConfig()
+		cfg.HealthEnabled = true
+		cfg.HealthPath = "healthz"
+
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "admin_server.health_path")
+	})
+
+	t.Run("readiness path under pprof fails", func(t *testing.T) {
+		cfg := validAdminServerConfig()
+		cfg.ReadinessEnabled = true
+		cfg.ReadinessPath = "/debug/pprof/ready"
+
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "admin_server.readiness_path")
+	})
+
+	t.Run("identical health and readiness paths fail", func(t *testing.T) {
+		cfg := validAdminServerConfig()
+		cfg.HealthEnabled = true
+		cfg.ReadinessEnabled = true
+		cfg.ReadinessPath = cfg.HealthPath
+
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "must differ")
+	})
+}
+
+func TestAdminServerConfig_ValidateAgainst(t *testing.T) {
+	public := config.ServerConfig{
+		Host:         "0.0.0.0",
+		Port:         8080,
+		ReadTimeout:  15 * time.Second,
+		WriteTimeout: 15 * time.Second,
+		IdleTimeout:  60 * time.Second,
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(cfg *config.AdminServerConfig)
+		collide bool
+	}{
+		{name: "different ports", modify: func(*config.AdminServerConfig) {}},
+		{name: "same port under public wildcard", modify: func(cfg *config.AdminServerConfig) { cfg.Port = 8080 }, collide: true},
+		{name: "same tcp listen address", modify: func(cfg *config.AdminServerConfig) { cfg.ListenAddr = "tcp://127.0.0.1:8080" }, collide: true},
+		{name: "ephemeral ports", modify: func(cfg *config.AdminServerConfig) { cfg.Port = 0 }},
+		{name: "unix socket", modify: func(cfg *config.AdminServerConfig) { cfg.ListenAddr = "unix:///tmp/admin.sock" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validAdminServerConfig()
+			tt.modify(&cfg)
+
+			err := cfg.ValidateAgainst(public)
+			if tt.collide {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "collides")
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+
+	t.Run("same unix socket collides", func(t *testing.T) {
+		cfg := validAdminServerConfig()
+		cfg.ListenAddr = "unix:///tmp/app.sock"
+		p := public
+		p.ListenAddr = "unix:///tmp/app.sock"
+
+		err := cfg.ValidateAgainst(p)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "collides")
+	})
+
+	t.Run("localhost and loopback collide", func(t *testing.T) {
+		cfg := validAdminServerConfig()
+		p := public
+		p.Host = "localhost"
+		p.Port = cfg.Port
+
+		require.Error(t, cfg.ValidateAgainst(p))
+	})
+}
+
+func TestAdminServerConfig_NewMux(t *testing.T) {
+	get := func(t *testing.T, handler http.Handler, path string) (int, string) {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		body, err := io.ReadAll(rec.Result().Body)
+		require.NoError(t, err)
+		return rec.Code, string(body)
+	}
+
+	t.Run("mounts nothing by default", func(t *testing.T) {
+		cfg := validAdminServerConfig()
+		mux := cfg.NewMux(nil)
+
+		for _, path := range []string{"/healthz", "/readyz", "/debug/pprof/"} {
+			code, _ := get(t, mux, path)
+			assert.Equal(t, http.StatusNotFound, code, path)
+		}
+	})
+
+	t.Run("serves enabled handlers", func(t *testing.T) {
+		cfg := validAdminServerConfig()
+		cfg.HealthEnabled = true
+		cfg.HealthPath = "/livez"
+		cfg.ReadinessEnabled = true
+		cfg.PprofEnabled = true
+		mux := cfg.NewMux(nil)
+
+		code, body := get(t, mux, "/livez")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, "ok\n", body)
+
+		code, _ = get(t, mux, "/readyz")
+		assert.Equal(t, http.StatusOK, code)
+
+		code, body = get(t, mux, "/debug/pprof/")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Contains(t, body, "goroutine")
+	})
+
+	t.Run("readiness reports check failure", func(t *testing.T) {
+		cfg := validAdminServerConfig()
+		cfg.ReadinessEnabled = true
+		mux := cfg.NewMux(func(context.Context) error {
+			return errors.New("database unavailable")
+		})
+
+		code, body := get(t, mux, "/readyz")
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Contains(t, body, "database unavailable")
+	})
+}
@@ -0,0 +1,110 @@
+package config
+
+import (
+	"iter"
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// Jitter strategies for ResilienceConfig.Jitter
+const (
+	// JitterNone uses the exponential delays unchanged
+	JitterNone = "none"
+	// JitterFull picks a random delay between zero and the exponential delay
+	JitterFull = "full"
+	// JitterEqual keeps half the exponential delay and randomises the other half
+	JitterEqual = "equal"
+	// JitterDecorrelated picks a random delay between InitialDelay and the
+	// previous delay times Multiplier, independent of the attempt number
+	JitterDecorrelated = "decorrelated"
+)
+
+// baseDelay returns the delay before retry attempt (starting at 1), without jitter
+func (c *ResilienceConfig) baseDelay(attempt int) time.Duration {
+	delay := float64(c.InitialDelay) * math.Pow(c.Multiplier, float64(attempt-1))
+	if delay > float64(c.MaxDelay) {
+		return c.MaxDelay
+	}
+	return time.Duration(delay)
+}
+
+// Backoff produces the delays between retries described by a
+// ResilienceConfig: InitialDelay growing by Multiplier per attempt, capped at
+// MaxDelay, with the configured Jitter applied, for at most MaxRetries
+// retries. Use one Backoff per retry loop; it is not safe for concurrent use.
+type Backoff struct {
+	config  ResilienceConfig
+	rng     *rand.Rand
+	attempt int
+	prev    time.Duration
+}
+
+// Backoff returns a Backoff for the retry settings in c
+func (c *ResilienceConfig) Backoff() *Backoff {
+	return c.SeededBackoff(rand.Uint64())
+}
+
+// SeededBackoff returns a Backoff whose jitter is drawn from a generator
+// seeded with seed, so the same seed always yields the same delays. It is
+// intended for tests.
+func (c *ResilienceConfig) SeededBackoff(seed uint64) *Backoff {
+	b := &Backoff{
+		config: *c,
+		rng:    rand.New(rand.NewPCG(seed, seed)), // #nosec G404 -- jitter does not need a secure source
+	}
+	b.Reset()
+	return b
+}
+
+// Next returns the delay before the next retry. It returns false once
+// MaxRetries delays have been produced.
+func (b *Backoff) Next() (time.Duration, bool) {
+	if b.attempt >= b.config.MaxRetries {
+		return 0, false
+	}
+	b.attempt++
+
+	base := b.config.baseDelay(b.attempt)
+	var delay time.Duration
+	switch b.config.Jitter {
+	case JitterFull:
+		delay = b.between(0, base)
+	case JitterEqual:
+		delay = base/2 + b.between(0, base-base/2)
+	case JitterDecorrelated:
+		upper := time.Duration(float64(b.prev) * b.config.Multiplier)
+		delay = min(b.between(b.config.InitialDelay, upper), b.config.MaxDelay)
+	default:
+		delay = base
+	}
+
+	b.prev = delay
+	return delay, true
+}
+
+// Reset starts the schedule again from the first retry
+func (b *Backoff) Reset() {
+	b.attempt = 0
+	b.prev = b.config.InitialDelay
+}
+
+// Delays returns the remaining delays in the schedule
+func (b *Backoff) Delays() iter.Seq[time.Duration] {
+	return func(yield func(time.Duration) bool) {
+		for {
+			delay, ok := b.Next()
+			if !ok || !yield(delay) {
+				return
+			}
+		}
+	}
+}
+
+// between returns a random duration in [lo, hi]
+func (b *Backoff) between(lo, hi time.Duration) time.Duration {
+	if hi <= lo {
+		return lo
+	}
+	return lo + time.Duration(b.rng.Int64N(int64(hi-lo)+1))
+}
@@ -0,0 +1,119 @@
+package config_test
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	config "github.com/JohnPlummer/jp-go-config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackoff(t *testing.T) {
+	resilience := func(jitter string) config.ResilienceConfig {
+		return config.ResilienceConfig{
+			MaxRetries:   5,
+			InitialDelay: 100 * time.Millisecond,
+			MaxDelay:     time.Second,
+			Multiplier:   2,
+			Jitter:       jitter,
+		}
+	}
+
+	t.Run("produces capped exponential delays without jitter", func(t *testing.T) {
+		cfg := resilience(config.JitterNone)
+		backoff := cfg.Backoff()
+
+		assert.Equal(t, []time.Duration{
+			100 * time.Millisecond,
+			200 * time.Millisecond,
+			400 * time.Millisecond,
+			800 * time.Millisecond,
+			time.Second,
+		}, slices.Collect(backoff.Delays()))
+
+		_, ok := backoff.Next()
+		assert.False(t, ok, "stops after MaxRetries")
+	})
+
+	t.Run("grows by the multiplier up to MaxDelay", func(t *testing.T) {
+		cfg := config.ResilienceConfig{
+			MaxRetries:   4,
+			InitialDelay: time.Second,
+			MaxDelay:     5 * time.Second,
+			Multiplier:   2,
+		}
+
+		assert.Equal(t, []time.Duration{
+			time.Second,
+			2 * time.Second,
+			4 * time.Second,
+			5 * time.Second,
+		}, slices.Collect(cfg.Backoff().Delays()))
+	})
+
+	t.Run("reset restarts the schedule", func(t *testing.T) {
+		cfg := resilience(config.JitterNone)
+		backoff := cfg.Backoff()
+
+		backoff.Next()
+		backoff.Next()
+		backoff.Reset()
+
+		delay, ok := backoff.Next()
+		require.True(t, ok)
+		assert.Equal(t, 100*time.Millisecond, delay)
+	})
+
+	t.Run("jitter stays within bounds", func(t *testing.T) {
+		for _, jitter := range []string{config.JitterFull, config.JitterEqual, config.JitterDecorrelated} {
+			cfg := resilience(jitter)
+			unjittered := resilience(config.JitterNone)
+			bases := slices.Collect(unjittered.Backoff().Delays())
+			for seed := uint64(0); seed < 50; seed++ {
+				backoff := cfg.SeededBackoff(seed)
+				attempt := 0
+				for delay := range backoff.Delays() {
+					base := bases[attempt]
+					attempt++
+
+					assert.LessOrEqual(t, delay, cfg.MaxDelay, jitter)
+					switch jitter {
+					case config.JitterFull:
+						assert.LessOrEqual(t, delay, base, jitter)
+					case config.JitterEqual:
+						assert.GreaterOrEqual(t, delay, base/2, jitter)
+						assert.LessOrEqual(t, delay, base, jitter)
+					case config.JitterDecorrelated:
+						assert.GreaterOrEqual(t, delay, cfg.InitialDelay, jitter)
+					}
+				}
+				assert.Equal(t, cfg.MaxRetries, attempt, jitter)
+			}
+		}
+	})
+
+	t.Run("seeded backoffs are deterministic", func(t *testing.T) {
+		cfg := resilience(config.JitterFull)
+
+		first := slices.Collect(cfg.SeededBackoff(42).Delays())
+		second := slices.Collect(cfg.SeededBackoff(42).Delays())
+		other := slices.Collect(cfg.SeededBackoff(7).Delays())
+
+		assert.Equal(t, first, second)
+		assert.NotEqual(t, first, other)
+	})
+
+	t.Run("invalid jitter fails validation", func(t *testing.T) {
+		cfg := resilience("random")
+		cfg.MaxRequests = 10
+		cfg.Interval = 10 * time.Second
+		cfg.Timeout = time.Minute
+		cfg.FailureThreshold = 0.5
+
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "resilience.jitter")
+	})
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrBulkheadFull is returned by Bulkhead.Acquire when no slot became free
+// within MaxQueueWait
+var ErrBulkheadFull = errors.New("bulkhead is full")
+
+// Bulkhead caps the number of concurrent calls to a dependency at
+// MaxConcurrent, so that a slow dependency cannot tie up every goroutine. It
+// is safe for concurrent use.
+type Bulkhead struct {
+	slots chan struct{}
+	wait  time.Duration
+}
+
+// NewBulkhead returns a Bulkhead using MaxConcurrent and MaxQueueWait from c.
+// With MaxConcurrent unset, it admits every call.
+func (c *ResilienceConfig) NewBulkhead() *Bulkhead {
+	b := &Bulkhead{wait: c.MaxQueueWait}
+	if c.MaxConcurrent > 0 {
+		b.slots = make(chan struct{}, c.MaxConcurrent)
+	}
+	return b
+}
+
+// Acquire waits for a free slot and returns a function that releases it. It
+// fails with ErrBulkheadFull after MaxQueueWait, or with the context's error.
+func (b *Bulkhead) Acquire(ctx context.Context) (release func(), err error) {
+	if b.slots == nil {
+		return func() {}, nil
+	}
+
+	var timeout <-chan time.Time
+	if b.wait > 0 {
+		timer := time.NewTimer(b.wait)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case b.slots <- struct{}{}:
+		return func() { <-b.slots }, nil
+	case <-timeout:
+		return nil, ErrBulkheadFull
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Execute calls fn while holding a slot
+func (b *Bulkhead) Execute(ctx context.Context, fn func(ctx context.Context) error) error {
+	release, err := b.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+	return fn(ctx)
+}
+
+// InFlight returns the number of slots in use
+func (b *Bulkhead) InFlight() int {
+	return len(b.slots)
+}
@@ -0,0 +1,64 @@
+package config_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	config "github.com/JohnPlummer/jp-go-config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBulkhead(t *testing.T) {
+	t.Run("caps concurrent calls", func(t *testing.T) {
+		cfg := config.ResilienceConfig{MaxConcurrent: 2, MaxQueueWait: 20 * time.Millisecond}
+		bulkhead := cfg.NewBulkhead()
+
+		first, err := bulkhead.Acquire(context.Background())
+		require.NoError(t, err)
+		second, err := bulkhead.Acquire(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 2, bulkhead.InFlight())
+
+		_, err = bulkhead.Acquire(context.Background())
+		assert.ErrorIs(t, err, config.ErrBulkheadFull)
+
+		first()
+		third, err := bulkhead.Acquire(context.Background())
+		require.NoError(t, err)
+		second()
+		third()
+		assert.Equal(t, 0, bulkhead.InFlight())
+	})
+
+	t.Run("waits for the context without a queue wait", func(t *testing.T) {
+		cfg := config.ResilienceConfig{MaxConcurrent: 1}
+		bulkhead := cfg.NewBulkhead()
+
+		release, err := bulkhead.Acquire(context.Background())
+		require.NoError(t, err)
+		defer release()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		err = bulkhead.Execute(ctx, func(context.Context) error { return nil })
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("admits every call when unlimited", func(t *testing.T) {
+		cfg := config.ResilienceConfig{}
+		bulkhead := cfg.NewBulkhead()
+
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				assert.NoError(t, bulkhead.Execute(context.Background(), func(context.Context) error { return nil }))
+			}()
+		}
+		wg.Wait()
+	})
+}
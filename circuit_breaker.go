@@ -0,0 +1,309 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// CircuitState is the state of a CircuitBreaker
+type CircuitState int
+
+// Circuit breaker states
+const (
+	// CircuitClosed lets requests through and tracks their failure ratio
+	CircuitClosed CircuitState = iota
+	// CircuitHalfOpen lets up to MaxRequests probe requests through
+	CircuitHalfOpen
+	// CircuitOpen rejects requests until Timeout has passed
+	CircuitOpen
+)
+
+// String returns the state name
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitHalfOpen:
+		return "half-open"
+	case CircuitOpen:
+		return "open"
+	}
+	return fmt.Sprintf("CircuitState(%d)", int(s))
+}
+
+// Errors returned by CircuitBreaker.Execute without calling the function
+var (
+	ErrCircuitOpen     = errors.New("circuit breaker is open")
+	ErrTooManyRequests = errors.New("circuit breaker is half-open and all probe requests are in use")
+)
+
+// circuitWindowBuckets is the number of buckets the rolling window over
+// Interval is divided into
+const circuitWindowBuckets = 10
+
+// defaultCircuitMinRequests is the number of requests the rolling window must
+// hold before the failure ratio can open the circuit
+const defaultCircuitMinRequests = 5
+
+// CircuitBreaker stops calling a failing dependency, using the circuit
+// breaker settings of a ResilienceConfig:
+//
+//   - Closed: requests pass through. When at least the minimum number of
+//     requests (default 5) in the last Interval failed at a ratio of
+//     FailureThreshold or more, the circuit opens.
+//   - Open: requests fail with ErrCircuitOpen. After Timeout the circuit
+//     becomes half-open.
+//   - Half-open: up to MaxRequests probe requests pass; others fail with
+//     ErrTooManyRequests. A failure reopens the circuit, and MaxRequests
+//     successes close it.
+//
+// It is safe for concurrent use.
+type CircuitBreaker struct {
+	maxRequests uint32
+	interval    time.Duration
+	timeout     time.Duration
+	threshold   float64
+	minRequests int
+	now         func() time.Time
+	isFailure   func(error) bool
+	onChange    []func(from, to CircuitState)
+
+	mu         sync.Mutex
+	state      CircuitState
+	generation uint64
+	openedAt   time.Time
+	window     [circuitWindowBuckets]circuitBucket
+	probes     uint32
+	successes  uint32
+}
+
+// circuitBucket counts requests in one slice of the rolling window
+type circuitBucket struct {
+	slot     int64
+	requests int
+	failures int
+}
+
+// CircuitBreakerOption customises a CircuitBreaker
+type CircuitBreakerOption func(*CircuitBreaker)
+
+// WithBreakerClock sets the clock used for the window and timeout, for tests
+func WithBreakerClock(now func() time.Time) CircuitBreakerOption {
+	return func(b *CircuitBreaker) {
+		b.now = now
+	}
+}
+
+// WithBreakerMinRequests sets how many requests the rolling window must hold
+// before the circuit can open
+func WithBreakerMinRequests(n int) CircuitBreakerOption {
+	return func(b *CircuitBreaker) {
+		b.minRequests = n
+	}
+}
+
+// WithBreakerFailure decides which errors count as failures. By default every
+// error does except context.Canceled, which reflects the caller rather than
+// the dependency.
+func WithBreakerFailure(isFailure func(error) bool) CircuitBreakerOption {
+	return func(b *CircuitBreaker) {
+		b.isFailure = isFailure
+	}
+}
+
+// WithStateChange registers fn to be called after each state change. It is
+// called without the breaker's lock held, so it may call State.
+func WithStateChange(fn func(from, to CircuitState)) CircuitBreakerOption {
+	return func(b *CircuitBreaker) {
+		b.onChange = append(b.onChange, fn)
+	}
+}
+
+// NewCircuitBreaker returns a closed CircuitBreaker using MaxRequests,
+// Interval, Timeout and FailureThreshold from c
+func (c *ResilienceConfig) NewCircuitBreaker(opts ...CircuitBreakerOption) *CircuitBreaker {
+	b := &CircuitBreaker{
+		maxRequests: max(c.MaxRequests, 1),
+		interval:    c.Interval,
+		timeout:     c.Timeout,
+		threshold:   c.FailureThreshold,
+		minRequests: defaultCircuitMinRequests,
+		now:         time.Now,
+		isFailure: func(err error) bool {
+			return !errors.Is(err, context.Canceled)
+		},
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+// State returns the current state
+func (b *CircuitBreaker) State() CircuitState {
+	b.mu.Lock()
+	state, changed := b.currentState()
+	b.mu.Unlock()
+
+	b.notify(changed)
+	return state
+}
+
+// Execute calls fn if the circuit allows it and records the outcome. When the
+// circuit rejects the call, fn is not called and ErrCircuitOpen or
+// ErrTooManyRequests is returned. A panic in fn counts as a failure and is
+// propagated.
+func (b *CircuitBreaker) Execute(ctx context.Context, fn func(ctx context.Context) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	generation, err := b.before()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			b.after(generation, true)
+			panic(r)
+		}
+	}()
+
+	err = fn(ctx)
+	b.after(generation, err != nil && b.isFailure(err))
+	return err
+}
+
+// before admits a request, returning the generation it belongs to
+func (b *CircuitBreaker) before() (uint64, error) {
+	b.mu.Lock()
+	state, changed := b.currentState()
+	var err error
+	switch state {
+	case CircuitOpen:
+		err = ErrCircuitOpen
+	case CircuitHalfOpen:
+		if b.probes >= b.maxRequests {
+			err = ErrTooManyRequests
+		} else {
+			b.probes++
+		}
+	}
+	generation := b.generation
+	b.mu.Unlock()
+
+	b.notify(changed)
+	return generation, err
+}
+
+// after records the outcome of a request admitted in generation. Outcomes
+// from an earlier generation, such as a slow request that started before the
+// circuit opened, are ignored.
+func (b *CircuitBreaker) after(generation uint64, failed bool) {
+	b.mu.Lock()
+	state, changed := b.currentState()
+	if generation == b.generation {
+		switch state {
+		case CircuitClosed:
+			b.record(failed)
+			if b.tripped() {
+				changed = append(changed, b.setState(CircuitOpen)...)
+			}
+		case CircuitHalfOpen:
+			if failed {
+				changed = append(changed, b.setState(CircuitOpen)...)
+			} else if b.successes++; b.successes >= b.maxRequests {
+				changed = append(changed, b.setState(CircuitClosed)...)
+			}
+		}
+	}
+	b.mu.Unlock()
+
+	b.notify(changed)
+}
+
+// currentState moves an open circuit to half-open once Timeout has passed.
+// Callers must hold b.mu.
+func (b *CircuitBreaker) currentState() (CircuitState, []circuitChange) {
+	if b.state == CircuitOpen && !b.now().Before(b.openedAt.Add(b.timeout)) {
+		return CircuitHalfOpen, b.setState(CircuitHalfOpen)
+	}
+	return b.state, nil
+}
+
+// circuitChange is a state transition waiting to be reported
+type circuitChange struct {
+	from, to CircuitState
+}
+
+// setState switches to state and starts a new generation. Callers must hold
+// b.mu and pass the returned change to notify after unlocking.
+func (b *CircuitBreaker) setState(state CircuitState) []circuitChange {
+	if b.state == state {
+		return nil
+	}
+	change := circuitChange{from: b.state, to: state}
+
+	b.state = state
+	b.generation++
+	b.window = [circuitWindowBuckets]circuitBucket{}
+	b.probes = 0
+	b.successes = 0
+	if state == CircuitOpen {
+		b.openedAt = b.now()
+	}
+	return []circuitChange{change}
+}
+
+// notify calls the state change callbacks
+func (b *CircuitBreaker) notify(changes []circuitChange) {
+	for _, change := range changes {
+		for _, fn := range b.onChange {
+			fn(change.from, change.to)
+		}
+	}
+}
+
+// slot returns the index of the window bucket covering now. Without an
+// Interval, all requests share one bucket until the state changes.
+func (b *CircuitBreaker) slot() int64 {
+	if b.interval <= 0 {
+		return 0
+	}
+	width := int64(b.interval) / circuitWindowBuckets
+	return b.now().UnixNano() / max(width, 1)
+}
+
+// record counts a request in the rolling window. Callers must hold b.mu.
+func (b *CircuitBreaker) record(failed bool) {
+	slot := b.slot()
+	bucket := &b.window[slot%circuitWindowBuckets]
+	if bucket.slot != slot {
+		*bucket = circuitBucket{slot: slot}
+	}
+	bucket.requests++
+	if failed {
+		bucket.failures++
+	}
+}
+
+// tripped reports whether the failure ratio over the rolling window has
+// reached the threshold. Callers must hold b.mu.
+func (b *CircuitBreaker) tripped() bool {
+	slot := b.slot()
+	requests, failures := 0, 0
+	for _, bucket := range b.window {
+		if bucket.requests > 0 && slot-bucket.slot < circuitWindowBuckets {
+			requests += bucket.requests
+			failures += bucket.failures
+		}
+	}
+	if failures == 0 || requests < b.minRequests {
+		return false
+	}
+	return float64(failures)/float64(requests) >= b.threshold
+}
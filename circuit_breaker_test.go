@@ -0,0 +1,244 @@
+package config_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	config "github.com/JohnPlummer/jp-go-config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock is a manually advanced clock for circuit breaker and limiter tests
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []fakeTimer
+}
+
+// fakeTimer is a pending After call
+type fakeTimer struct {
+	at time.Time
+	ch chan time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// After returns a channel that receives once the clock is advanced by d
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ch := make(chan time.Time, 1)
+	c.waiters = append(c.waiters, fakeTimer{at: c.now.Add(d), ch: ch})
+	return ch
+}
+
+// Waiters returns the number of pending After calls
+func (c *fakeClock) Waiters() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.waiters)
+}
+
+// Advance moves the clock forward by d and fires the After calls now due
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+
+	pending := c.waiters[:0]
+	for _, w := range c.waiters {
+		if w.at.After(c.now) {
+			pending = append(pending, w)
+			continue
+		}
+		w.ch <- c.now
+	}
+	c.waiters = pending
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	errBoom := errors.New("boom")
+	fail := func(context.Context) error { return errBoom }
+	succeed := func(context.Context) error { return nil }
+
+	newBreaker := func(opts ...config.CircuitBreakerOption) (*config.CircuitBreaker, *fakeClock) {
+		clock := &fakeClock{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+		cfg := config.ResilienceConfig{
+			MaxRequests:      2,
+			Interval:         10 * time.Second,
+			Timeout:          30 * time.Second,
+			FailureThreshold: 0.5,
+		}
+		opts = append([]config.CircuitBreakerOption{config.WithBreakerClock(clock.Now)}, opts...)
+		return cfg.NewCircuitBreaker(opts...), clock
+	}
+
+	t.Run("opens when the failure ratio reaches the threshold", func(t *testing.T) {
+		breaker, _ := newBreaker()
+		ctx := context.Background()
+
+		for i := 0; i < 3; i++ {
+			require.NoError(t, breaker.Execute(ctx, succeed))
+		}
+		require.ErrorIs(t, breaker.Execute(ctx, fail), errBoom)
+		require.ErrorIs(t, breaker.Execute(ctx, fail), errBoom)
+		assert.Equal(t, config.CircuitClosed, breaker.State(), "2 of 5 failed")
+
+		require.ErrorIs(t, breaker.Execute(ctx, fail), errBoom)
+		assert.Equal(t, config.CircuitOpen, breaker.State(), "3 of 6 failed")
+
+		called := false
+		err := breaker.Execute(ctx, func(context.Context) error {
+			called = true
+			return nil
+		})
+		assert.ErrorIs(t, err, config.ErrCircuitOpen)
+		assert.False(t, called)
+	})
+
+	t.Run("waits for the minimum number of requests", func(t *testing.T) {
+		breaker, _ := newBreaker()
+
+		for i := 0; i < 4; i++ {
+			_ = breaker.Execute(context.Background(), fail)
+		}
+		assert.Equal(t, config.CircuitClosed, breaker.State())
+
+		_ = breaker.Execute(context.Background(), fail)
+		assert.Equal(t, config.CircuitOpen, breaker.State())
+	})
+
+	t.Run("forgets failures outside the interval", func(t *testing.T) {
+		breaker, clock := newBreaker()
+
+		for i := 0; i < 4; i++ {
+			_ = breaker.Execute(context.Background(), fail)
+		}
+		clock.Advance(11 * time.Second)
+		_ = breaker.Execute(context.Background(), fail)
+
+		assert.Equal(t, config.CircuitClosed, breaker.State())
+	})
+
+	t.Run("probes after the timeout and closes on success", func(t *testing.T) {
+		var changes []string
+		breaker, clock := newBreaker(
+			config.WithBreakerMinRequests(1),
+			config.WithStateChange(func(from, to config.CircuitState) {
+				changes = append(changes, from.String()+"->"+to.String())
+			}),
+		)
+		ctx := context.Background()
+
+		_ = breaker.Execute(ctx, fail)
+		clock.Advance(30 * time.Second)
+		assert.Equal(t, config.CircuitHalfOpen, breaker.State())
+
+		require.NoError(t, breaker.Execute(ctx, succeed))
+		assert.Equal(t, config.CircuitHalfOpen, breaker.State())
+		require.NoError(t, breaker.Execute(ctx, succeed))
+		assert.Equal(t, config.CircuitClosed, breaker.State())
+
+		assert.Equal(t, []string{"closed->open", "open->half-open", "half-open->closed"}, changes)
+	})
+
+	t.Run("reopens when a probe fails", func(t *testing.T) {
+		breaker, clock := newBreaker(config.WithBreakerMinRequests(1))
+		ctx := context.Background()
+
+		_ = breaker.Execute(ctx, fail)
+		clock.Advance(30 * time.Second)
+		_ = breaker.Execute(ctx, fail)
+
+		assert.Equal(t, config.CircuitOpen, breaker.State())
+		assert.ErrorIs(t, breaker.Execute(ctx, succeed), config.ErrCircuitOpen)
+	})
+
+	t.Run("limits half-open probes to max requests", func(t *testing.T) {
+		breaker, clock := newBreaker(config.WithBreakerMinRequests(1))
+		ctx := context.Background()
+
+		_ = breaker.Execute(ctx, fail)
+		clock.Advance(30 * time.Second)
+
+		release := make(chan struct{})
+		var wg sync.WaitGroup
+		started := make(chan struct{}, 2)
+		for i := 0; i < 2; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_ = breaker.Execute(ctx, func(context.Context) error {
+					started <- struct{}{}
+					<-release
+					return nil
+				})
+			}()
+		}
+		<-started
+		<-started
+
+		assert.ErrorIs(t, breaker.Execute(ctx, succeed), config.ErrTooManyRequests)
+		close(release)
+		wg.Wait()
+		assert.Equal(t, config.CircuitClosed, breaker.State())
+	})
+
+	t.Run("does not count cancellations or custom non-failures", func(t *testing.T) {
+		errNotFound := errors.New("not found")
+		breaker, _ := newBreaker(
+			config.WithBreakerMinRequests(1),
+			config.WithBreakerFailure(func(err error) bool { return !errors.Is(err, errNotFound) }),
+		)
+
+		_ = breaker.Execute(context.Background(), func(context.Context) error { return errNotFound })
+		assert.Equal(t, config.CircuitClosed, breaker.State())
+	})
+
+	t.Run("counts panics as failures", func(t *testing.T) {
+		breaker, _ := newBreaker(config.WithBreakerMinRequests(1))
+
+		assert.Panics(t, func() {
+			_ = breaker.Execute(context.Background(), func(context.Context) error { panic("boom") })
+		})
+		assert.Equal(t, config.CircuitOpen, breaker.State())
+	})
+
+	t.Run("is safe for concurrent use", func(t *testing.T) {
+		var transitions atomic.Int32
+		breaker, clock := newBreaker(config.WithStateChange(func(from, to config.CircuitState) {
+			transitions.Add(1)
+		}))
+
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					fn := succeed
+					if (i+j)%2 == 0 {
+						fn = fail
+					}
+					_ = breaker.Execute(context.Background(), fn)
+					if j%10 == 0 {
+						clock.Advance(time.Second)
+					}
+					_ = breaker.State()
+				}
+			}(i)
+		}
+		wg.Wait()
+
+		assert.Positive(t, transitions.Load())
+	})
+}
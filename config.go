@@ -7,7 +7,9 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/spf13/viper"
@@ -189,6 +191,141 @@ func (s *Standard) Viper() *viper.Viper {
 	return s.viper
 }
 
+// stringOr returns the string value for key, or fallback if key is not set
+func (s *Standard) stringOr(key, fallback string) string {
+	if s.viper.IsSet(key) {
+		return s.viper.GetString(key)
+	}
+	return fallback
+}
+
+// intOr returns the integer value for key, or fallback if key is not set
+func (s *Standard) intOr(key string, fallback int) int {
+	if s.viper.IsSet(key) {
+		return s.viper.GetInt(key)
+	}
+	return fallback
+}
+
+// int64Or returns the int64 value for key, or fallback if key is not set
+func (s *Standard) int64Or(key string, fallback int64) int64 {
+	if s.viper.IsSet(key) {
+		return s.viper.GetInt64(key)
+	}
+	return fallback
+}
+
+// uint32Or returns the uint32 value for key, or fallback if key is not set
+func (s *Standard) uint32Or(key string, fallback uint32) uint32 {
+	if s.viper.IsSet(key) {
+		return s.viper.GetUint32(key)
+	}
+	return fallback
+}
+
+// floatOr returns the float value for key, or fallback if key is not set
+func (s *Standard) floatOr(key string, fallback float64) float64 {
+	if s.viper.IsSet(key) {
+		return s.viper.GetFloat64(key)
+	}
+	return fallback
+}
+
+// boolOr returns the boolean value for key, or fallback if key is not set
+func (s *Standard) boolOr(key string, fallback bool) bool {
+	if s.viper.IsSet(key) {
+		return s.viper.GetBool(key)
+	}
+	return fallback
+}
+
+// durationOr returns the duration value for key, or fallback if key is not set
+func (s *Standard) durationOr(key string, fallback time.Duration) time.Duration {
+	if s.viper.IsSet(key) {
+		return s.viper.GetDuration(key)
+	}
+	return fallback
+}
+
+// stringMapOr returns the string map value for key, or fallback if key is not set
+func (s *Standard) stringMapOr(key string, fallback map[string]string) map[string]string {
+	if s.viper.IsSet(key) {
+		return s.viper.GetStringMapString(key)
+	}
+	return fallback
+}
+
+// stringList returns the string list value for key. A single string value,
+// such as one read from an environment variable, is split on commas.
+func (s *Standard) stringList(key string) []string {
+	value, ok := s.viper.Get(key).(string)
+	if !ok {
+		return s.viper.GetStringSlice(key)
+	}
+
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// stringListOr returns the string list value for key, or fallback if key is
+// not set
+func (s *Standard) stringListOr(key string, fallback []string) []string {
+	if s.viper.IsSet(key) {
+		return s.stringList(key)
+	}
+	return fallback
+}
+
+// intListOr returns the integer list value for key, or fallback if key is not
+// set. A single string value is split on commas; entries that are not
+// integers are left out and the first of them is returned as invalid so that
+// validation can report it.
+func (s *Standard) intListOr(key string, fallback []int) (list []int, invalid string) {
+	if !s.viper.IsSet(key) {
+		return fallback, ""
+	}
+	if _, ok := s.viper.Get(key).(string); !ok {
+		return s.viper.GetIntSlice(key), ""
+	}
+
+	for _, item := range s.stringList(key) {
+		n, err := strconv.Atoi(item)
+		if err != nil {
+			if invalid == "" {
+				invalid = item
+			}
+			continue
+		}
+		list = append(list, n)
+	}
+	return list, invalid
+}
+
+// envPrefixClash returns the first of suffixes, the environment variables of
+// a primary block after its prefix, that the variables of the instance called
+// name would shadow, such as DIAL_TIMEOUT for "dial". It returns "" when there
+// is none.
+func envPrefixClash(name string, suffixes []string) string {
+	prefix := envName(name) + "_"
+	for _, suffix := range suffixes {
+		if strings.HasPrefix(suffix, prefix) {
+			return suffix
+		}
+	}
+	return ""
+}
+
+// envName converts an instance name into its environment variable form,
+// e.g. "read-replica" becomes "READ_REPLICA".
+func envName(name string) string {
+	return strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(name))
+}
+
 // LoadEnvFile loads environment variables from a .env file.
 // Does not override existing environment variables.
 // Silently succeeds if the file doesn't exist.
@@ -1,12 +1,35 @@
 package config
 
 import (
+	"context"
+	"database/sql/driver"
 	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 )
 
-// DatabaseConfig holds PostgreSQL database configuration with connection pooling settings.
+// Supported database drivers
+const (
+	DriverPostgres = "postgres"
+	DriverMySQL    = "mysql"
+	DriverSQLite   = "sqlite"
+)
+
+// DatabaseConfig holds database configuration with connection pooling settings.
+// PostgreSQL is the default driver; MySQL and SQLite are selected with Driver.
 type DatabaseConfig struct {
+	// Name identifies a named instance loaded with DatabaseConfigFromViperNamed.
+	// It is empty for the primary database.
+	Name string `mapstructure:"-"`
+
+	// Driver selects the database driver: postgres (default), mysql or sqlite
+	Driver string `mapstructure:"driver"`
+
 	Host     string `mapstructure:"host"`
 	Port     int    `mapstructure:"port"`
 	Database string `mapstructure:"database"`
@@ -14,6 +37,10 @@ type DatabaseConfig struct {
 	Password string `mapstructure:"password"`
 	SSLMode  string `mapstructure:"ssl_mode"`
 
+	// PasswordProvider supplies short-lived credentials such as RDS IAM tokens
+	// or Vault dynamic secrets. When nil, Password is used as a static password.
+	PasswordProvider PasswordProvider `mapstructure:"-"`
+
 	// Connection pool settings
 	MaxConns        int           `mapstructure:"max_conns"`
 	MinConns        int           `mapstructure:"min_conns"`
@@ -26,15 +53,31 @@ type DatabaseConfig struct {
 
 	// Health check
 	HealthCheckPeriod time.Duration `mapstructure:"health_check_period"`
+
+	// Session and driver parameters. Zero values leave the server default in place.
+	ApplicationName                 string        `mapstructure:"application_name"`
+	SearchPath                      string        `mapstructure:"search_path"`
+	StatementTimeout                time.Duration `mapstructure:"statement_timeout"`
+	LockTimeout                     time.Duration `mapstructure:"lock_timeout"`
+	IdleInTransactionSessionTimeout time.Duration `mapstructure:"idle_in_transaction_session_timeout"`
+	ConnectTimeout                  time.Duration `mapstructure:"connect_timeout"`
+
+	// Extra holds additional connection parameters passed through verbatim
+	Extra map[string]string `mapstructure:"extra"`
+
+	// Driver-specific settings
+	MySQL  MySQLOptions  `mapstructure:"mysql"`
+	SQLite SQLiteOptions `mapstructure:"sqlite"`
 }
 
 // DatabaseConfigFromViper creates a DatabaseConfig from a Standard config loader.
 //
 // Environment variable mappings:
+//   - DB_DRIVER -> driver (default: postgres)
 //   - DB_HOST -> host (default: localhost)
-//   - DB_PORT -> port (default: 5432)
+//   - DB_PORT -> port (default: 5432, or 3306 for mysql)
 //   - DB_NAME or DB_DATABASE -> database (default: postgres)
-//   - DB_USER or DB_USERNAME -> user (default: postgres)
+//   - DB_USER or DB_USERNAME -> user (default: postgres, or root for mysql)
 //   - DB_PASSWORD or DB_PASS -> password
 //   - DB_SSLMODE -> ssl_mode (default: disable)
 //   - DB_MAX_CONNS -> max_conns (default: 25)
@@ -44,37 +87,28 @@ type DatabaseConfig struct {
 //   - DB_RETRY_ATTEMPTS -> retry_attempts (default: 3)
 //   - DB_RETRY_DELAY -> retry_delay (default: 2s)
 //   - DB_HEALTH_CHECK_PERIOD -> health_check_period (default: 30s)
+//   - DB_APPLICATION_NAME -> application_name
+//   - DB_SEARCH_PATH -> search_path (comma-separated schemas)
+//   - DB_STATEMENT_TIMEOUT -> statement_timeout
+//   - DB_LOCK_TIMEOUT -> lock_timeout
+//   - DB_IDLE_IN_TRANSACTION_SESSION_TIMEOUT -> idle_in_transaction_session_timeout
+//   - DB_CONNECT_TIMEOUT -> connect_timeout
+//   - DB_MYSQL_TLS -> mysql.tls
+//   - DB_MYSQL_PARSE_TIME -> mysql.parse_time (default: true)
+//   - DB_MYSQL_LOC -> mysql.loc (default: UTC)
+//   - DB_MYSQL_CHARSET -> mysql.charset (default: utf8mb4)
+//   - DB_SQLITE_PATH -> sqlite.path (required for sqlite)
+//   - DB_SQLITE_JOURNAL_MODE -> sqlite.journal_mode (default: WAL)
+//   - DB_SQLITE_BUSY_TIMEOUT -> sqlite.busy_timeout (default: 5s)
+//
+// Additional connection parameters can be set in the database.extra map.
+// Settings that only apply to PostgreSQL (ssl_mode, application_name,
+// search_path and the session timeouts) are ignored or rejected for other drivers.
 func DatabaseConfigFromViper(s *Standard) DatabaseConfig {
 	// Bind environment variables
-	_ = s.BindEnv("database.host", "DB_HOST")
-	_ = s.BindEnv("database.port", "DB_PORT")
-	_ = s.BindEnv("database.database", "DB_NAME", "DB_DATABASE")
-	_ = s.BindEnv("database.user", "DB_USER", "DB_USERNAME")
-	_ = s.BindEnv("database.password", "DB_PASSWORD", "DB_PASS")
-	_ = s.BindEnv("database.ssl_mode", "DB_SSLMODE")
-	_ = s.BindEnv("database.max_conns", "DB_MAX_CONNS")
-	_ = s.BindEnv("database.min_conns", "DB_MIN_CONNS")
-	_ = s.BindEnv("database.conn_max_lifetime", "DB_CONN_MAX_LIFETIME")
-	_ = s.BindEnv("database.conn_max_idle_time", "DB_CONN_MAX_IDLE_TIME")
-	_ = s.BindEnv("database.retry_attempts", "DB_RETRY_ATTEMPTS")
-	_ = s.BindEnv("database.retry_delay", "DB_RETRY_DELAY")
-	_ = s.BindEnv("database.health_check_period", "DB_HEALTH_CHECK_PERIOD")
-
-	config := DatabaseConfig{
-		Host:              s.GetString("database.host"),
-		Port:              s.GetInt("database.port"),
-		Database:          s.GetString("database.database"),
-		User:              s.GetString("database.user"),
-		Password:          s.GetString("database.password"),
-		SSLMode:           s.GetString("database.ssl_mode"),
-		MaxConns:          s.GetInt("database.max_conns"),
-		MinConns:          s.GetInt("database.min_conns"),
-		ConnMaxLifetime:   s.viper.GetDuration("database.conn_max_lifetime"),
-		ConnMaxIdleTime:   s.viper.GetDuration("database.conn_max_idle_time"),
-		RetryAttempts:     s.GetInt("database.retry_attempts"),
-		RetryDelay:        s.viper.GetDuration("database.retry_delay"),
-		HealthCheckPeriod: s.viper.GetDuration("database.health_check_period"),
-	}
+	bindDatabaseEnv(s, "database.", "DB_")
+
+	config := loadDatabaseConfig(s, "database.", databaseFallback())
 
 	// Apply defaults
 	config.setDefaults()
@@ -82,23 +116,169 @@ func DatabaseConfigFromViper(s *Standard) DatabaseConfig {
 	return config
 }
 
-// setDefaults sets default values for optional fields
-func (c *DatabaseConfig) setDefaults() {
-	if c.Host == "" {
-		c.Host = "localhost"
+// DatabaseConfigFromViperNamed creates a DatabaseConfig for a named database
+// instance, such as a separate analytics database alongside the primary one.
+//
+// Values are read from databases.<name>.* and <NAME>_DB_* environment variables
+// (e.g. ANALYTICS_DB_HOST for "analytics"). Any field that is not set for the
+// instance falls back to the primary database block, then to the defaults.
+// When the instance uses a different driver from the primary database, only
+// the pool, retry and health check settings are inherited.
+func DatabaseConfigFromViperNamed(s *Standard, name string) DatabaseConfig {
+	bindDatabaseEnv(s, "database.", "DB_")
+	primary := loadDatabaseConfig(s, "database.", databaseFallback())
+
+	prefix := "databases." + name + "."
+	bindDatabaseEnv(s, prefix, envName(name)+"_DB_")
+
+	fallback := primary
+	if driver := s.stringOr(prefix+"driver", primary.Driver); !sameDriver(driver, primary.Driver) {
+		fallback = primary.sharedSettings()
 	}
-	if c.Port == 0 {
-		c.Port = 5432
+
+	config := loadDatabaseConfig(s, prefix, fallback)
+	config.Name = name
+
+	// Apply defaults
+	config.setDefaults()
+
+	return config
+}
+
+// loadDatabaseConfig reads the database keys under prefix, using the values in
+// fallback for any key that is not set.
+func loadDatabaseConfig(s *Standard, prefix string, fallback DatabaseConfig) DatabaseConfig {
+	return DatabaseConfig{
+		Driver:            s.stringOr(prefix+"driver", fallback.Driver),
+		Host:              s.stringOr(prefix+"host", fallback.Host),
+		Port:              s.intOr(prefix+"port", fallback.Port),
+		Database:          s.stringOr(prefix+"database", fallback.Database),
+		User:              s.stringOr(prefix+"user", fallback.User),
+		Password:          s.stringOr(prefix+"password", fallback.Password),
+		SSLMode:           s.stringOr(prefix+"ssl_mode", fallback.SSLMode),
+		MaxConns:          s.intOr(prefix+"max_conns", fallback.MaxConns),
+		MinConns:          s.intOr(prefix+"min_conns", fallback.MinConns),
+		ConnMaxLifetime:   s.durationOr(prefix+"conn_max_lifetime", fallback.ConnMaxLifetime),
+		ConnMaxIdleTime:   s.durationOr(prefix+"conn_max_idle_time", fallback.ConnMaxIdleTime),
+		RetryAttempts:     s.intOr(prefix+"retry_attempts", fallback.RetryAttempts),
+		RetryDelay:        s.durationOr(prefix+"retry_delay", fallback.RetryDelay),
+		HealthCheckPeriod: s.durationOr(prefix+"health_check_period", fallback.HealthCheckPeriod),
+
+		ApplicationName:                 s.stringOr(prefix+"application_name", fallback.ApplicationName),
+		SearchPath:                      s.stringOr(prefix+"search_path", fallback.SearchPath),
+		StatementTimeout:                s.durationOr(prefix+"statement_timeout", fallback.StatementTimeout),
+		LockTimeout:                     s.durationOr(prefix+"lock_timeout", fallback.LockTimeout),
+		IdleInTransactionSessionTimeout: s.durationOr(prefix+"idle_in_transaction_session_timeout", fallback.IdleInTransactionSessionTimeout),
+		ConnectTimeout:                  s.durationOr(prefix+"connect_timeout", fallback.ConnectTimeout),
+		Extra:                           s.stringMapOr(prefix+"extra", fallback.Extra),
+
+		MySQL: MySQLOptions{
+			TLS:       s.stringOr(prefix+"mysql.tls", fallback.MySQL.TLS),
+			ParseTime: s.boolOr(prefix+"mysql.parse_time", fallback.MySQL.ParseTime),
+			Loc:       s.stringOr(prefix+"mysql.loc", fallback.MySQL.Loc),
+			Charset:   s.stringOr(prefix+"mysql.charset", fallback.MySQL.Charset),
+		},
+		SQLite: SQLiteOptions{
+			Path:        s.stringOr(prefix+"sqlite.path", fallback.SQLite.Path),
+			JournalMode: s.stringOr(prefix+"sqlite.journal_mode", fallback.SQLite.JournalMode),
+			BusyTimeout: s.durationOr(prefix+"sqlite.busy_timeout", fallback.SQLite.BusyTimeout),
+		},
 	}
-	if c.Database == "" {
-		c.Database = "postgres"
+}
+
+// databaseFallback returns the starting point for loading a database config.
+// It carries defaults that cannot be applied in setDefaults because their zero
+// value is meaningful, such as mysql.parse_time.
+func databaseFallback() DatabaseConfig {
+	return DatabaseConfig{
+		MySQL: MySQLOptions{ParseTime: true},
 	}
-	if c.User == "" {
-		c.User = "postgres"
+}
+
+// sharedSettings returns the driver-independent pool, retry and health check
+// settings of c, for inheritance by named instances using another driver.
+func (c *DatabaseConfig) sharedSettings() DatabaseConfig {
+	shared := databaseFallback()
+	shared.MaxConns = c.MaxConns
+	shared.MinConns = c.MinConns
+	shared.ConnMaxLifetime = c.ConnMaxLifetime
+	shared.ConnMaxIdleTime = c.ConnMaxIdleTime
+	shared.RetryAttempts = c.RetryAttempts
+	shared.RetryDelay = c.RetryDelay
+	shared.HealthCheckPeriod = c.HealthCheckPeriod
+	return shared
+}
+
+// bindDatabaseEnv binds the database keys under prefix to environment
+// variables starting with envPrefix.
+func bindDatabaseEnv(s *Standard, prefix, envPrefix string) {
+	_ = s.BindEnv(prefix+"driver", envPrefix+"DRIVER")
+	_ = s.BindEnv(prefix+"host", envPrefix+"HOST")
+	_ = s.BindEnv(prefix+"port", envPrefix+"PORT")
+	_ = s.BindEnv(prefix+"database", envPrefix+"NAME", envPrefix+"DATABASE")
+	_ = s.BindEnv(prefix+"user", envPrefix+"USER", envPrefix+"USERNAME")
+	_ = s.BindEnv(prefix+"password", envPrefix+"PASSWORD", envPrefix+"PASS")
+	_ = s.BindEnv(prefix+"ssl_mode", envPrefix+"SSLMODE")
+	_ = s.BindEnv(prefix+"max_conns", envPrefix+"MAX_CONNS")
+	_ = s.BindEnv(prefix+"min_conns", envPrefix+"MIN_CONNS")
+	_ = s.BindEnv(prefix+"conn_max_lifetime", envPrefix+"CONN_MAX_LIFETIME")
+	_ = s.BindEnv(prefix+"conn_max_idle_time", envPrefix+"CONN_MAX_IDLE_TIME")
+	_ = s.BindEnv(prefix+"retry_attempts", envPrefix+"RETRY_ATTEMPTS")
+	_ = s.BindEnv(prefix+"retry_delay", envPrefix+"RETRY_DELAY")
+	_ = s.BindEnv(prefix+"health_check_period", envPrefix+"HEALTH_CHECK_PERIOD")
+	_ = s.BindEnv(prefix+"application_name", envPrefix+"APPLICATION_NAME")
+	_ = s.BindEnv(prefix+"search_path", envPrefix+"SEARCH_PATH")
+	_ = s.BindEnv(prefix+"statement_timeout", envPrefix+"STATEMENT_TIMEOUT")
+	_ = s.BindEnv(prefix+"lock_timeout", envPrefix+"LOCK_TIMEOUT")
+	_ = s.BindEnv(prefix+"idle_in_transaction_session_timeout", envPrefix+"IDLE_IN_TRANSACTION_SESSION_TIMEOUT")
+	_ = s.BindEnv(prefix+"connect_timeout", envPrefix+"CONNECT_TIMEOUT")
+	_ = s.BindEnv(prefix+"mysql.tls", envPrefix+"MYSQL_TLS")
+	_ = s.BindEnv(prefix+"mysql.parse_time", envPrefix+"MYSQL_PARSE_TIME")
+	_ = s.BindEnv(prefix+"mysql.loc", envPrefix+"MYSQL_LOC")
+	_ = s.BindEnv(prefix+"mysql.charset", envPrefix+"MYSQL_CHARSET")
+	_ = s.BindEnv(prefix+"sqlite.path", envPrefix+"SQLITE_PATH")
+	_ = s.BindEnv(prefix+"sqlite.journal_mode", envPrefix+"SQLITE_JOURNAL_MODE")
+	_ = s.BindEnv(prefix+"sqlite.busy_timeout", envPrefix+"SQLITE_BUSY_TIMEOUT")
+}
+
+// setDefaults sets default values for optional fields
+func (c *DatabaseConfig) setDefaults() {
+	if c.Driver == "" {
+		c.Driver = DriverPostgres
 	}
-	if c.SSLMode == "" {
-		c.SSLMode = "disable"
+
+	switch c.Driver {
+	case DriverPostgres:
+		if c.Host == "" {
+			c.Host = "localhost"
+		}
+		if c.Port == 0 {
+			c.Port = 5432
+		}
+		if c.Database == "" {
+			c.Database = "postgres"
+		}
+		if c.User == "" {
+			c.User = "postgres"
+		}
+		if c.SSLMode == "" {
+			c.SSLMode = "disable"
+		}
+	case DriverMySQL:
+		if c.Host == "" {
+			c.Host = "localhost"
+		}
+		if c.Port == 0 {
+			c.Port = 3306
+		}
+		if c.User == "" {
+			c.User = "root"
+		}
+		c.MySQL.setDefaults()
+	case DriverSQLite:
+		c.SQLite.setDefaults()
 	}
+
 	if c.MaxConns == 0 {
 		c.MaxConns = 25
 	}
@@ -124,19 +304,72 @@ func (c *DatabaseConfig) setDefaults() {
 
 // Validate validates the database configuration
 func (c *DatabaseConfig) Validate() error {
-	if err := ValidateRequired("database.host", c.Host); err != nil {
+	switch c.driver() {
+	case DriverPostgres:
+		if err := c.validatePostgres(); err != nil {
+			return err
+		}
+	case DriverMySQL:
+		if err := c.validateMySQL(); err != nil {
+			return err
+		}
+	case DriverSQLite:
+		if err := c.validateSQLite(); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("%s must be one of: %v", c.key("driver"),
+			[]string{DriverPostgres, DriverMySQL, DriverSQLite})
+	}
+
+	// Validate connection pool settings
+	if err := ValidatePositive(c.key("max_conns"), c.MaxConns); err != nil {
+		return err
+	}
+	if err := ValidateRange(c.key("min_conns"), c.MinConns, 0, c.MaxConns); err != nil {
 		return err
 	}
-	if err := ValidatePort("database.port", c.Port); err != nil {
+	if err := ValidateDuration(c.key("conn_max_lifetime"), c.ConnMaxLifetime); err != nil {
 		return err
 	}
-	if err := ValidateRequired("database.database", c.Database); err != nil {
+	if err := ValidateDuration(c.key("conn_max_idle_time"), c.ConnMaxIdleTime); err != nil {
 		return err
 	}
-	if err := ValidateRequired("database.user", c.User); err != nil {
+
+	// Validate retry settings
+	if err := ValidateRange(c.key("retry_attempts"), c.RetryAttempts, 0, 10); err != nil {
 		return err
 	}
-	if err := ValidateRequired("database.password", c.Password); err != nil {
+	if err := ValidateDuration(c.key("retry_delay"), c.RetryDelay); err != nil {
+		return err
+	}
+	if err := ValidateDuration(c.key("health_check_period"), c.HealthCheckPeriod); err != nil {
+		return err
+	}
+
+	// Validate session parameters
+	if c.driver() == DriverPostgres {
+		if err := c.validatePostgresSession(); err != nil {
+			return err
+		}
+	} else if err := c.validateNoPostgresSession(); err != nil {
+		return err
+	}
+	if err := ValidateDuration(c.key("connect_timeout"), c.ConnectTimeout); err != nil {
+		return err
+	}
+	for name := range c.Extra {
+		if name == "" {
+			return fmt.Errorf("%s must not contain empty parameter names", c.key("extra"))
+		}
+	}
+
+	return nil
+}
+
+// validatePostgres validates the PostgreSQL connection settings
+func (c *DatabaseConfig) validatePostgres() error {
+	if err := c.validateServer(); err != nil {
 		return err
 	}
 
@@ -150,45 +383,359 @@ func (c *DatabaseConfig) Validate() error {
 		}
 	}
 	if !valid {
-		return fmt.Errorf("database.ssl_mode must be one of: %v", validSSLModes)
+		return fmt.Errorf("%s must be one of: %v", c.key("ssl_mode"), validSSLModes)
 	}
 
-	// Validate connection pool settings
-	if err := ValidatePositive("database.max_conns", c.MaxConns); err != nil {
+	return nil
+}
+
+// validateServer validates the connection settings shared by network drivers
+func (c *DatabaseConfig) validateServer() error {
+	if err := ValidateRequired(c.key("host"), c.Host); err != nil {
 		return err
 	}
-	if err := ValidateRange("database.min_conns", c.MinConns, 0, c.MaxConns); err != nil {
+	if err := ValidatePort(c.key("port"), c.Port); err != nil {
 		return err
 	}
-	if err := ValidateDuration("database.conn_max_lifetime", c.ConnMaxLifetime); err != nil {
+	if err := ValidateRequired(c.key("database"), c.Database); err != nil {
 		return err
 	}
-	if err := ValidateDuration("database.conn_max_idle_time", c.ConnMaxIdleTime); err != nil {
+	if err := ValidateRequired(c.key("user"), c.User); err != nil {
 		return err
 	}
+	if c.PasswordProvider == nil {
+		if err := ValidateRequired(c.key("password"), c.Password); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-	// Validate retry settings
-	if err := ValidateRange("database.retry_attempts", c.RetryAttempts, 0, 10); err != nil {
+// validatePostgresSession validates the PostgreSQL session parameters
+func (c *DatabaseConfig) validatePostgresSession() error {
+	if len(c.ApplicationName) > maxIdentifierLength {
+		return fmt.Errorf("%s must be at most %d characters, got %d",
+			c.key("application_name"), maxIdentifierLength, len(c.ApplicationName))
+	}
+	if c.SearchPath != "" {
+		for _, schema := range strings.Split(c.SearchPath, ",") {
+			if err := validateSchemaName(strings.TrimSpace(schema)); err != nil {
+				return fmt.Errorf("%s %w", c.key("search_path"), err)
+			}
+		}
+	}
+	if err := ValidateDuration(c.key("statement_timeout"), c.StatementTimeout); err != nil {
 		return err
 	}
-	if err := ValidateDuration("database.retry_delay", c.RetryDelay); err != nil {
+	if err := ValidateDuration(c.key("lock_timeout"), c.LockTimeout); err != nil {
 		return err
 	}
-	if err := ValidateDuration("database.health_check_period", c.HealthCheckPeriod); err != nil {
+	if err := ValidateDuration(c.key("idle_in_transaction_session_timeout"), c.IdleInTransactionSessionTimeout); err != nil {
 		return err
 	}
+	return nil
+}
+
+// validateNoPostgresSession rejects PostgreSQL session parameters on other
+// drivers, where they would otherwise be silently ignored.
+func (c *DatabaseConfig) validateNoPostgresSession() error {
+	for _, param := range []struct {
+		field string
+		set   bool
+	}{
+		{"application_name", c.ApplicationName != ""},
+		{"search_path", c.SearchPath != ""},
+		{"statement_timeout", c.StatementTimeout != 0},
+		{"lock_timeout", c.LockTimeout != 0},
+		{"idle_in_transaction_session_timeout", c.IdleInTransactionSessionTimeout != 0},
+	} {
+		if param.set {
+			return fmt.Errorf("%s is only supported by the %s driver", c.key(param.field), DriverPostgres)
+		}
+	}
+	return nil
+}
+
+// driver returns the configured driver, treating an empty Driver as postgres
+func (c *DatabaseConfig) driver() string {
+	if c.Driver == "" {
+		return DriverPostgres
+	}
+	return c.Driver
+}
+
+// sameDriver reports whether two driver names refer to the same driver
+func sameDriver(a, b string) bool {
+	if a == "" {
+		a = DriverPostgres
+	}
+	if b == "" {
+		b = DriverPostgres
+	}
+	return a == b
+}
+
+// maxIdentifierLength is the PostgreSQL identifier length limit (NAMEDATALEN - 1)
+const maxIdentifierLength = 63
 
+// schemaNamePattern matches an unquoted PostgreSQL identifier
+var schemaNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_$]*$`)
+
+// validateSchemaName validates a single search_path entry
+func validateSchemaName(schema string) error {
+	if schema == "$user" || schema == `"$user"` {
+		return nil
+	}
+	if err := validateIdentifier(schema); err != nil {
+		return fmt.Errorf("entry %w", err)
+	}
 	return nil
 }
 
-// ConnectionString returns a PostgreSQL connection string
+// validateIdentifier validates an unquoted PostgreSQL identifier
+func validateIdentifier(name string) error {
+	if len(name) > maxIdentifierLength {
+		return fmt.Errorf("%q must be at most %d characters", name, maxIdentifierLength)
+	}
+	if !schemaNamePattern.MatchString(name) {
+		return fmt.Errorf("%q is not a valid identifier", name)
+	}
+	return nil
+}
+
+// key returns the fully qualified config key for field, used in validation
+// errors so that named instances can be told apart.
+func (c *DatabaseConfig) key(field string) string {
+	if c.Name == "" {
+		return "database." + field
+	}
+	return "databases." + c.Name + "." + field
+}
+
+// ConnectionString returns a connection string for the configured driver using
+// the static Password. Use ConnectionStringContext when a PasswordProvider is
+// configured.
+//
+// PostgreSQL uses the postgres:// URL form, MySQL the go-sql-driver/mysql DSN
+// form and SQLite a file: URI.
 func (c *DatabaseConfig) ConnectionString() string {
-	return fmt.Sprintf("postgres://%s:%s@%s:%d/%s?sslmode=%s",
-		c.User,
-		c.Password,
-		c.Host,
-		c.Port,
-		c.Database,
-		c.SSLMode,
-	)
+	return c.connectionString(c.Password)
+}
+
+// ConnectionStringContext returns a connection string for the configured
+// driver with a password fetched from the configured PasswordProvider.
+func (c *DatabaseConfig) ConnectionStringContext(ctx context.Context) (string, error) {
+	password, err := c.CurrentPassword(ctx)
+	if err != nil {
+		return "", err
+	}
+	return c.connectionString(password), nil
+}
+
+// CurrentPassword returns the password to use for a new connection, taken from
+// the PasswordProvider if set or the static Password otherwise.
+func (c *DatabaseConfig) CurrentPassword(ctx context.Context) (string, error) {
+	provider := c.PasswordProvider
+	if provider == nil {
+		provider = StaticPassword(c.Password)
+	}
+
+	password, _, err := provider.Password(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get password for %s: %w", c.key("password"), err)
+	}
+	return password, nil
+}
+
+// connectionString builds the connection string for the configured driver
+func (c *DatabaseConfig) connectionString(password string) string {
+	switch c.driver() {
+	case DriverMySQL:
+		return c.mysqlConnectionString(password)
+	case DriverSQLite:
+		return c.sqliteConnectionString()
+	default:
+		return c.postgresConnectionString(password)
+	}
+}
+
+// postgresConnectionString builds a PostgreSQL URL connection string with password
+func (c *DatabaseConfig) postgresConnectionString(password string) string {
+	u := url.URL{
+		Scheme: "postgres",
+		User:   url.UserPassword(c.User, password),
+		Host:   net.JoinHostPort(c.Host, strconv.Itoa(c.Port)),
+		Path:   "/" + c.Database,
+	}
+
+	params := c.params()
+	query := make([]string, 0, len(params))
+	for _, p := range params {
+		query = append(query, queryEscape(p.name)+"="+queryEscape(p.value))
+	}
+	u.RawQuery = strings.Join(query, "&")
+
+	return u.String()
+}
+
+// KeyValueConnectionString returns a libpq key/value connection string
+// (host=... port=... dbname=...) using the static Password. Drivers other than
+// postgres have no key/value form and return ConnectionString instead.
+func (c *DatabaseConfig) KeyValueConnectionString() string {
+	return c.keyValueConnectionString(c.Password)
+}
+
+// KeyValueConnectionStringContext returns a libpq key/value connection string
+// with a password fetched from the configured PasswordProvider.
+func (c *DatabaseConfig) KeyValueConnectionStringContext(ctx context.Context) (string, error) {
+	password, err := c.CurrentPassword(ctx)
+	if err != nil {
+		return "", err
+	}
+	return c.keyValueConnectionString(password), nil
+}
+
+// keyValueConnectionString builds a libpq key/value connection string with password
+func (c *DatabaseConfig) keyValueConnectionString(password string) string {
+	if c.driver() != DriverPostgres {
+		return c.connectionString(password)
+	}
+
+	params := append([]connParam{
+		{"host", c.Host},
+		{"port", strconv.Itoa(c.Port)},
+		{"dbname", c.Database},
+		{"user", c.User},
+		{"password", password},
+	}, c.params()...)
+
+	pairs := make([]string, 0, len(params))
+	for _, p := range params {
+		pairs = append(pairs, p.name+"="+keyValueQuote(p.value))
+	}
+	return strings.Join(pairs, " ")
+}
+
+// connParam is a single connection string parameter
+type connParam struct {
+	name  string
+	value string
+}
+
+// params returns the PostgreSQL connection parameters shared by the URL and
+// key/value formats, in a stable order.
+//
+// Session settings without a libpq keyword (search_path and the timeouts) are
+// sent through the options parameter as -c flags, which every PostgreSQL
+// driver forwards to the server at connection start-up.
+func (c *DatabaseConfig) params() []connParam {
+	params := []connParam{{"sslmode", c.SSLMode}}
+	if c.ApplicationName != "" {
+		params = append(params, connParam{"application_name", c.ApplicationName})
+	}
+	if c.ConnectTimeout > 0 {
+		params = append(params, connParam{"connect_timeout", strconv.FormatInt(ceilDuration(c.ConnectTimeout, time.Second), 10)})
+	}
+
+	var options []string
+	if c.SearchPath != "" {
+		schemas := strings.Split(c.SearchPath, ",")
+		for i := range schemas {
+			schemas[i] = strings.TrimSpace(schemas[i])
+		}
+		options = append(options, "-c search_path="+strings.Join(schemas, ","))
+	}
+	if c.StatementTimeout > 0 {
+		options = append(options, "-c statement_timeout="+strconv.FormatInt(ceilDuration(c.StatementTimeout, time.Millisecond), 10))
+	}
+	if c.LockTimeout > 0 {
+		options = append(options, "-c lock_timeout="+strconv.FormatInt(ceilDuration(c.LockTimeout, time.Millisecond), 10))
+	}
+	if c.IdleInTransactionSessionTimeout > 0 {
+		options = append(options, "-c idle_in_transaction_session_timeout="+
+			strconv.FormatInt(ceilDuration(c.IdleInTransactionSessionTimeout, time.Millisecond), 10))
+	}
+
+	for _, name := range sortedKeys(c.Extra) {
+		if name == "options" {
+			options = append([]string{c.Extra[name]}, options...)
+			continue
+		}
+		params = append(params, connParam{name, c.Extra[name]})
+	}
+
+	if len(options) > 0 {
+		params = append(params, connParam{"options", strings.Join(options, " ")})
+	}
+
+	return params
+}
+
+// sortedKeys returns the keys of m in sorted order
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ceilDuration returns d as a whole number of units, rounding up so that a
+// small positive duration never becomes zero (which PostgreSQL reads as "off").
+func ceilDuration(d, unit time.Duration) int64 {
+	return int64((d + unit - 1) / unit)
+}
+
+// queryEscape escapes a URL query component, encoding spaces as %20 rather
+// than + because libpq does not decode + as a space.
+func queryEscape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+// keyValueQuote quotes a value for a libpq key/value connection string when
+// it is empty or contains spaces, quotes or backslashes.
+func keyValueQuote(value string) string {
+	if value != "" && !strings.ContainsAny(value, " \t\n'\\") {
+		return value
+	}
+	escaped := strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value)
+	return "'" + escaped + "'"
+}
+
+// Connector returns a driver.Connector that opens connections through drv,
+// building a fresh connection string with the current password for every new
+// connection. Use it with sql.OpenDB so that pooled connections pick up rotated
+// credentials:
+//
+//	db := sql.OpenDB(dbConfig.Connector(stdlib.GetDefaultDriver()))
+func (c *DatabaseConfig) Connector(drv driver.Driver) driver.Connector {
+	return &passwordConnector{config: *c, driver: drv}
+}
+
+// passwordConnector is a driver.Connector that resolves the password per connection
+type passwordConnector struct {
+	config DatabaseConfig
+	driver driver.Driver
+}
+
+// Connect opens a new connection using a freshly resolved connection string
+func (pc *passwordConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	dsn, err := pc.config.ConnectionStringContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if dc, ok := pc.driver.(driver.DriverContext); ok {
+		connector, err := dc.OpenConnector(dsn)
+		if err != nil {
+			return nil, err
+		}
+		return connector.Connect(ctx)
+	}
+	return pc.driver.Open(dsn)
+}
+
+// Driver returns the underlying driver
+func (pc *passwordConnector) Driver() driver.Driver {
+	return pc.driver
 }
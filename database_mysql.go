@@ -0,0 +1,99 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MySQLOptions holds settings specific to the mysql driver
+type MySQLOptions struct {
+	// TLS is the go-sql-driver/mysql tls parameter: true, false, skip-verify,
+	// preferred, or the name of a config registered with mysql.RegisterTLSConfig.
+	TLS       string `mapstructure:"tls"`
+	ParseTime bool   `mapstructure:"parse_time"`
+	Loc       string `mapstructure:"loc"`
+	Charset   string `mapstructure:"charset"`
+}
+
+// setDefaults sets default values for optional fields
+func (o *MySQLOptions) setDefaults() {
+	if o.Loc == "" {
+		o.Loc = "UTC"
+	}
+	if o.Charset == "" {
+		o.Charset = "utf8mb4"
+	}
+}
+
+// charsetPattern matches a MySQL character set name, or a comma-separated
+// list of them in order of preference
+var charsetPattern = regexp.MustCompile(`^[a-z0-9_]+(,[a-z0-9_]+)*$`)
+
+// validateMySQL validates the MySQL connection settings
+func (c *DatabaseConfig) validateMySQL() error {
+	if err := c.validateServer(); err != nil {
+		return err
+	}
+	// The driver splits user from password at the first colon
+	if strings.Contains(c.User, ":") {
+		return fmt.Errorf("%s must not contain ':' for the mysql driver", c.key("user"))
+	}
+	if c.MySQL.Charset != "" && !charsetPattern.MatchString(c.MySQL.Charset) {
+		return fmt.Errorf("%s must be a character set name, got %q", c.key("mysql.charset"), c.MySQL.Charset)
+	}
+	if c.MySQL.Loc != "" {
+		if _, err := time.LoadLocation(c.MySQL.Loc); err != nil {
+			return fmt.Errorf("%s must be a valid time zone: %w", c.key("mysql.loc"), err)
+		}
+	}
+	if strings.ContainsAny(c.MySQL.TLS, " &=?") {
+		return fmt.Errorf("%s must be true, false, skip-verify, preferred or a registered config name, got %q",
+			c.key("mysql.tls"), c.MySQL.TLS)
+	}
+	return nil
+}
+
+// mysqlConnectionString builds a go-sql-driver/mysql DSN with password:
+// user:password@tcp(host:port)/database?params
+//
+// It follows mysql.Config.FormatDSN: the driver takes the credentials from
+// before the last '@' preceding the last '/', so user and password are written
+// as-is and may contain '@', '/' or '?'. The database name is path-escaped and
+// parameter values are query-escaped.
+func (c *DatabaseConfig) mysqlConnectionString(password string) string {
+	var params []connParam
+	if c.MySQL.Charset != "" {
+		params = append(params, connParam{"charset", c.MySQL.Charset})
+	}
+	if c.MySQL.Loc != "" {
+		params = append(params, connParam{"loc", c.MySQL.Loc})
+	}
+	params = append(params, connParam{"parseTime", strconv.FormatBool(c.MySQL.ParseTime)})
+	if c.ConnectTimeout > 0 {
+		params = append(params, connParam{"timeout", c.ConnectTimeout.String()})
+	}
+	if c.MySQL.TLS != "" {
+		params = append(params, connParam{"tls", c.MySQL.TLS})
+	}
+	for _, name := range sortedKeys(c.Extra) {
+		params = append(params, connParam{name, c.Extra[name]})
+	}
+
+	query := make([]string, 0, len(params))
+	for _, p := range params {
+		query = append(query, p.name+"="+url.QueryEscape(p.value))
+	}
+
+	return fmt.Sprintf("%s:%s@tcp(%s)/%s?%s",
+		c.User,
+		password,
+		net.JoinHostPort(c.Host, strconv.Itoa(c.Port)),
+		url.PathEscape(c.Database),
+		strings.Join(query, "&"),
+	)
+}
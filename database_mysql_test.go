@@ -0,0 +1,222 @@
+package config_test
+
+import (
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	config "github.com/JohnPlummer/jp-go-config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDatabaseConfig_MySQL(t *testing.T) {
+	t.Run("applies mysql defaults", func(t *testing.T) {
+		os.Setenv("DB_DRIVER", "mysql")
+		defer os.Unsetenv("DB_DRIVER")
+
+		std, err := config.NewStandard()
+		require.NoError(t, err)
+
+		cfg := config.DatabaseConfigFromViper(std)
+
+		assert.Equal(t, config.DriverMySQL, cfg.Driver)
+		assert.Equal(t, "localhost", cfg.Host)
+		assert.Equal(t, 3306, cfg.Port)
+		assert.Equal(t, "root", cfg.User)
+		assert.Equal(t, "", cfg.Database)
+		assert.Equal(t, "", cfg.SSLMode)
+		assert.True(t, cfg.MySQL.ParseTime)
+		assert.Equal(t, "UTC", cfg.MySQL.Loc)
+		assert.Equal(t, "utf8mb4", cfg.MySQL.Charset)
+		assert.Equal(t, 25, cfg.MaxConns)
+	})
+
+	t.Run("loads from environment variables", func(t *testing.T) {
+		os.Setenv("DB_DRIVER", "mysql")
+		os.Setenv("DB_MYSQL_TLS", "skip-verify")
+		os.Setenv("DB_MYSQL_PARSE_TIME", "false")
+		os.Setenv("DB_MYSQL_LOC", "Europe/London")
+		os.Setenv("DB_MYSQL_CHARSET", "utf8")
+		defer func() {
+			os.Unsetenv("DB_DRIVER")
+			os.Unsetenv("DB_MYSQL_TLS")
+			os.Unsetenv("DB_MYSQL_PARSE_TIME")
+			os.Unsetenv("DB_MYSQL_LOC")
+			os.Unsetenv("DB_MYSQL_CHARSET")
+		}()
+
+		std, err := config.NewStandard()
+		require.NoError(t, err)
+
+		cfg := config.DatabaseConfigFromViper(std)
+
+		assert.Equal(t, "skip-verify", cfg.MySQL.TLS)
+		assert.False(t, cfg.MySQL.ParseTime)
+		assert.Equal(t, "Europe/London", cfg.MySQL.Loc)
+		assert.Equal(t, "utf8", cfg.MySQL.Charset)
+	})
+
+	t.Run("named instance with another driver inherits only shared settings", func(t *testing.T) {
+		os.Setenv("DB_HOST", "pg-host")
+		os.Setenv("DB_MAX_CONNS", "40")
+		os.Setenv("LEGACY_DB_DRIVER", "mysql")
+		defer func() {
+			os.Unsetenv("DB_HOST")
+			os.Unsetenv("DB_MAX_CONNS")
+			os.Unsetenv("LEGACY_DB_DRIVER")
+		}()
+
+		std, err := config.NewStandard()
+		require.NoError(t, err)
+
+		cfg := config.DatabaseConfigFromViperNamed(std, "legacy")
+
+		assert.Equal(t, config.DriverMySQL, cfg.Driver)
+		assert.Equal(t, "localhost", cfg.Host)
+		assert.Equal(t, 3306, cfg.Port)
+		assert.Equal(t, 40, cfg.MaxConns)
+		assert.True(t, cfg.MySQL.ParseTime)
+	})
+}
+
+func TestDatabaseConfig_MySQL_Validate(t *testing.T) {
+	validConfig := func() config.DatabaseConfig {
+		return config.DatabaseConfig{
+			Driver:   config.DriverMySQL,
+			Host:     "localhost",
+			Port:     3306,
+			Database: "app",
+			User:     "root",
+			Password: "secret",
+			MaxConns: 25,
+			MySQL: config.MySQLOptions{
+				ParseTime: true,
+				Loc:       "UTC",
+				Charset:   "utf8mb4",
+			},
+		}
+	}
+
+	t.Run("valid config passes", func(t *testing.T) {
+		cfg := validConfig()
+		require.NoError(t, cfg.Validate())
+	})
+
+	t.Run("missing database fails", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.Database = ""
+
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "database.database is required")
+	})
+
+	t.Run("invalid location fails", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.MySQL.Loc = "Mars/Olympus_Mons"
+
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "database.mysql.loc must be a valid time zone")
+	})
+
+	t.Run("invalid charset fails", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.MySQL.Charset = "utf8; DROP"
+
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "database.mysql.charset")
+	})
+
+	t.Run("postgres session parameters fail", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.StatementTimeout = 30 * time.Second
+
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "database.statement_timeout is only supported by the postgres driver")
+	})
+
+	t.Run("unknown driver fails", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.Driver = "oracle"
+
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "database.driver must be one of")
+	})
+}
+
+func TestDatabaseConfig_MySQL_ConnectionString(t *testing.T) {
+	cfg := config.DatabaseConfig{
+		Driver:         config.DriverMySQL,
+		Host:           "db.example.com",
+		Port:           3306,
+		Database:       "app",
+		User:           "appuser",
+		Password:       "secret",
+		ConnectTimeout: 5 * time.Second,
+		Extra:          map[string]string{"readTimeout": "30s"},
+		MySQL: config.MySQLOptions{
+			TLS:       "true",
+			ParseTime: true,
+			Loc:       "Europe/London",
+			Charset:   "utf8mb4",
+		},
+	}
+
+	expected := "appuser:secret@tcp(db.example.com:3306)/app?charset=utf8mb4&loc=Europe%2FLondon" +
+		"&parseTime=true&timeout=5s&tls=true&readTimeout=30s"
+	assert.Equal(t, expected, cfg.ConnectionString())
+	assert.Equal(t, expected, cfg.KeyValueConnectionString())
+}
+
+// parseMySQLDSN splits a DSN the way go-sql-driver/mysql's ParseDSN does: the
+// database follows the last '/', the credentials precede the last '@' before
+// it, and the user ends at the first ':'.
+func parseMySQLDSN(t *testing.T, dsn string) (user, password, database string) {
+	t.Helper()
+
+	slash := strings.LastIndex(dsn, "/")
+	require.GreaterOrEqual(t, slash, 0)
+	at := strings.LastIndex(dsn[:slash], "@")
+	require.GreaterOrEqual(t, at, 0)
+	user, password, _ = strings.Cut(dsn[:at], ":")
+
+	database, _, _ = strings.Cut(dsn[slash+1:], "?")
+	database, err := url.PathUnescape(database)
+	require.NoError(t, err)
+	return user, password, database
+}
+
+func TestDatabaseConfig_MySQL_ConnectionStringSpecialCharacters(t *testing.T) {
+	cfg := config.DatabaseConfig{
+		Driver:   config.DriverMySQL,
+		Host:     "db.example.com",
+		Port:     3306,
+		Database: "app/reports?",
+		User:     "app@user",
+		Password: "p@ss/w:rd?&=#%",
+		MaxConns: 25,
+		MySQL:    config.MySQLOptions{Loc: "UTC"},
+	}
+	require.NoError(t, cfg.Validate())
+
+	user, password, database := parseMySQLDSN(t, cfg.ConnectionString())
+	assert.Equal(t, "app@user", user)
+	assert.Equal(t, "p@ss/w:rd?&=#%", password)
+	assert.Equal(t, "app/reports?", database)
+
+	t.Run("rejects a colon in the user", func(t *testing.T) {
+		cfg := cfg
+		cfg.User = "app:user"
+
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "database.user must not contain ':'")
+	})
+}
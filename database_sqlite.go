@@ -0,0 +1,110 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SQLiteOptions holds settings specific to the sqlite driver
+type SQLiteOptions struct {
+	Path        string        `mapstructure:"path"`
+	JournalMode string        `mapstructure:"journal_mode"`
+	BusyTimeout time.Duration `mapstructure:"busy_timeout"`
+}
+
+// setDefaults sets default values for optional fields
+func (o *SQLiteOptions) setDefaults() {
+	if o.JournalMode == "" {
+		o.JournalMode = "WAL"
+	}
+	if o.BusyTimeout == 0 {
+		o.BusyTimeout = 5 * time.Second
+	}
+}
+
+// sqliteMemoryPath is the special path for an in-memory SQLite database
+const sqliteMemoryPath = ":memory:"
+
+// validateSQLite validates the SQLite settings, including that the database
+// file's directory exists and is writable.
+func (c *DatabaseConfig) validateSQLite() error {
+	if err := ValidateRequired(c.key("sqlite.path"), c.SQLite.Path); err != nil {
+		return err
+	}
+
+	validJournalModes := []string{"DELETE", "TRUNCATE", "PERSIST", "MEMORY", "WAL", "OFF"}
+	valid := false
+	for _, mode := range validJournalModes {
+		if strings.EqualFold(c.SQLite.JournalMode, mode) {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return fmt.Errorf("%s must be one of: %v", c.key("sqlite.journal_mode"), validJournalModes)
+	}
+
+	if err := ValidateDuration(c.key("sqlite.busy_timeout"), c.SQLite.BusyTimeout); err != nil {
+		return err
+	}
+
+	if c.SQLite.Path == sqliteMemoryPath {
+		return nil
+	}
+	if err := checkWritableDir(filepath.Dir(c.SQLite.Path)); err != nil {
+		return fmt.Errorf("%s directory %w", c.key("sqlite.path"), err)
+	}
+
+	return nil
+}
+
+// checkWritableDir verifies that dir exists, is a directory and accepts new files
+func checkWritableDir(dir string) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return fmt.Errorf("%s is not accessible: %w", dir, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", dir)
+	}
+
+	f, err := os.CreateTemp(dir, ".write-check-*")
+	if err != nil {
+		return fmt.Errorf("%s is not writable: %w", dir, err)
+	}
+	name := f.Name()
+	_ = f.Close()
+	_ = os.Remove(name)
+
+	return nil
+}
+
+// sqliteConnectionString builds a SQLite file: URI with driver parameters
+func (c *DatabaseConfig) sqliteConnectionString() string {
+	var params []connParam
+	if c.SQLite.BusyTimeout > 0 {
+		params = append(params, connParam{"_busy_timeout", strconv.FormatInt(ceilDuration(c.SQLite.BusyTimeout, time.Millisecond), 10)})
+	}
+	if c.SQLite.JournalMode != "" {
+		params = append(params, connParam{"_journal_mode", c.SQLite.JournalMode})
+	}
+	for _, name := range sortedKeys(c.Extra) {
+		params = append(params, connParam{name, c.Extra[name]})
+	}
+
+	query := make([]string, 0, len(params))
+	for _, p := range params {
+		query = append(query, queryEscape(p.name)+"="+queryEscape(p.value))
+	}
+
+	dsn := "file:" + (&url.URL{Path: c.SQLite.Path}).EscapedPath()
+	if len(query) > 0 {
+		dsn += "?" + strings.Join(query, "&")
+	}
+	return dsn
+}
@@ -0,0 +1,137 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	config "github.com/JohnPlummer/jp-go-config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDatabaseConfig_SQLite(t *testing.T) {
+	t.Run("applies sqlite defaults", func(t *testing.T) {
+		os.Setenv("DB_DRIVER", "sqlite")
+		os.Setenv("DB_SQLITE_PATH", "/var/lib/app/app.db")
+		defer func() {
+			os.Unsetenv("DB_DRIVER")
+			os.Unsetenv("DB_SQLITE_PATH")
+		}()
+
+		std, err := config.NewStandard()
+		require.NoError(t, err)
+
+		cfg := config.DatabaseConfigFromViper(std)
+
+		assert.Equal(t, config.DriverSQLite, cfg.Driver)
+		assert.Equal(t, "/var/lib/app/app.db", cfg.SQLite.Path)
+		assert.Equal(t, "WAL", cfg.SQLite.JournalMode)
+		assert.Equal(t, 5*time.Second, cfg.SQLite.BusyTimeout)
+		assert.Equal(t, "", cfg.Host)
+		assert.Equal(t, 0, cfg.Port)
+		assert.Equal(t, 25, cfg.MaxConns)
+	})
+
+	t.Run("loads from environment variables", func(t *testing.T) {
+		os.Setenv("DB_DRIVER", "sqlite")
+		os.Setenv("DB_SQLITE_JOURNAL_MODE", "DELETE")
+		os.Setenv("DB_SQLITE_BUSY_TIMEOUT", "10s")
+		defer func() {
+			os.Unsetenv("DB_DRIVER")
+			os.Unsetenv("DB_SQLITE_JOURNAL_MODE")
+			os.Unsetenv("DB_SQLITE_BUSY_TIMEOUT")
+		}()
+
+		std, err := config.NewStandard()
+		require.NoError(t, err)
+
+		cfg := config.DatabaseConfigFromViper(std)
+
+		assert.Equal(t, "DELETE", cfg.SQLite.JournalMode)
+		assert.Equal(t, 10*time.Second, cfg.SQLite.BusyTimeout)
+	})
+}
+
+func TestDatabaseConfig_SQLite_Validate(t *testing.T) {
+	validConfig := func(path string) config.DatabaseConfig {
+		return config.DatabaseConfig{
+			Driver:   config.DriverSQLite,
+			MaxConns: 1,
+			MinConns: 1,
+			SQLite: config.SQLiteOptions{
+				Path:        path,
+				JournalMode: "WAL",
+				BusyTimeout: 5 * time.Second,
+			},
+		}
+	}
+
+	t.Run("valid config passes", func(t *testing.T) {
+		cfg := validConfig(filepath.Join(t.TempDir(), "app.db"))
+		require.NoError(t, cfg.Validate())
+	})
+
+	t.Run("in-memory database passes", func(t *testing.T) {
+		cfg := validConfig(":memory:")
+		require.NoError(t, cfg.Validate())
+	})
+
+	t.Run("missing path fails", func(t *testing.T) {
+		cfg := validConfig("")
+
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "database.sqlite.path is required")
+	})
+
+	t.Run("missing directory fails", func(t *testing.T) {
+		cfg := validConfig(filepath.Join(t.TempDir(), "missing", "app.db"))
+
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "database.sqlite.path directory")
+		assert.Contains(t, err.Error(), "is not accessible")
+	})
+
+	t.Run("read-only directory fails", func(t *testing.T) {
+		if os.Geteuid() == 0 {
+			t.Skip("root can write to read-only directories")
+		}
+		dir := t.TempDir()
+		require.NoError(t, os.Chmod(dir, 0o500))
+		t.Cleanup(func() { _ = os.Chmod(dir, 0o700) })
+
+		cfg := validConfig(filepath.Join(dir, "app.db"))
+
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "is not writable")
+	})
+
+	t.Run("invalid journal mode fails", func(t *testing.T) {
+		cfg := validConfig(":memory:")
+		cfg.SQLite.JournalMode = "FAST"
+
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "database.sqlite.journal_mode must be one of")
+	})
+}
+
+func TestDatabaseConfig_SQLite_ConnectionString(t *testing.T) {
+	cfg := config.DatabaseConfig{
+		Driver: config.DriverSQLite,
+		Extra:  map[string]string{"_foreign_keys": "on"},
+		SQLite: config.SQLiteOptions{
+			Path:        "/var/lib/my app/app.db",
+			JournalMode: "WAL",
+			BusyTimeout: 5 * time.Second,
+		},
+	}
+
+	assert.Equal(t,
+		"file:/var/lib/my%20app/app.db?_busy_timeout=5000&_journal_mode=WAL&_foreign_keys=on",
+		cfg.ConnectionString())
+}
@@ -0,0 +1,317 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Supported gRPC load balancing policies
+const (
+	GRPCPickFirst  = "pick_first"
+	GRPCRoundRobin = "round_robin"
+)
+
+// grpcStatusCodes lists the gRPC status code names accepted in
+// retryable_status_codes
+var grpcStatusCodes = map[string]bool{
+	"CANCELLED": true, "UNKNOWN": true, "INVALID_ARGUMENT": true,
+	"DEADLINE_EXCEEDED": true, "NOT_FOUND": true, "ALREADY_EXISTS": true,
+	"PERMISSION_DENIED": true, "RESOURCE_EXHAUSTED": true,
+	"FAILED_PRECONDITION": true, "ABORTED": true, "OUT_OF_RANGE": true,
+	"UNIMPLEMENTED": true, "INTERNAL": true, "UNAVAILABLE": true,
+	"DATA_LOSS": true, "UNAUTHENTICATED": true,
+}
+
+// GRPCClientConfig holds gRPC client configuration for one upstream. Like
+// GRPCServerConfig it only produces standard library values; the retry and
+// load balancing settings are applied through ServiceConfigJSON:
+//
+//	grpc.NewClient(cfg.Target,
+//		grpc.WithDefaultServiceConfig(serviceConfig),
+//		grpc.WithKeepaliveParams(keepalive.ClientParameters{
+//			Time:                cfg.KeepaliveTime,
+//			Timeout:             cfg.KeepaliveTimeout,
+//			PermitWithoutStream: cfg.KeepalivePermitWithoutStream,
+//		}),
+//	)
+type GRPCClientConfig struct {
+	// Name identifies a named instance loaded with GRPCClientConfigFromViperNamed.
+	// It is empty for the primary client.
+	Name string `mapstructure:"-"`
+
+	// Target is the gRPC dial target, e.g. dns:///payments:50051
+	Target string `mapstructure:"target"`
+
+	// Keepalive pings sent by the client. Zero KeepaliveTime disables them.
+	KeepaliveTime                time.Duration `mapstructure:"keepalive_time"`
+	KeepaliveTimeout             time.Duration `mapstructure:"keepalive_timeout"`
+	KeepalivePermitWithoutStream bool          `mapstructure:"keepalive_permit_without_stream"`
+
+	// LoadBalancingPolicy is pick_first (default) or round_robin
+	LoadBalancingPolicy string `mapstructure:"load_balancing_policy"`
+
+	// Timeout is the default deadline for calls that do not set one (0: none)
+	Timeout time.Duration `mapstructure:"timeout"`
+
+	// Retry policy. MaxAttempts includes the original call; 0 or 1 disables retries.
+	MaxAttempts          int           `mapstructure:"max_attempts"`
+	InitialBackoff       time.Duration `mapstructure:"initial_backoff"`
+	MaxBackoff           time.Duration `mapstructure:"max_backoff"`
+	BackoffMultiplier    float64       `mapstructure:"backoff_multiplier"`
+	RetryableStatusCodes []string      `mapstructure:"retryable_status_codes"`
+}
+
+// GRPCClientConfigFromViper creates a GRPCClientConfig from a Standard config loader.
+//
+// Environment variable mappings:
+//   - GRPC_CLIENT_TARGET -> target (required)
+//   - GRPC_CLIENT_KEEPALIVE_TIME -> keepalive_time
+//   - GRPC_CLIENT_KEEPALIVE_TIMEOUT -> keepalive_timeout (default: 20s)
+//   - GRPC_CLIENT_KEEPALIVE_PERMIT_WITHOUT_STREAM -> keepalive_permit_without_stream (default: false)
+//   - GRPC_CLIENT_LOAD_BALANCING_POLICY -> load_balancing_policy (default: pick_first)
+//   - GRPC_CLIENT_TIMEOUT -> timeout
+//   - GRPC_CLIENT_MAX_ATTEMPTS -> max_attempts (default: 1, no retries)
+//   - GRPC_CLIENT_INITIAL_BACKOFF -> initial_backoff (default: 100ms)
+//   - GRPC_CLIENT_MAX_BACKOFF -> max_backoff (default: 1s)
+//   - GRPC_CLIENT_BACKOFF_MULTIPLIER -> backoff_multiplier (default: 2.0)
+//   - GRPC_CLIENT_RETRYABLE_STATUS_CODES -> retryable_status_codes (default: UNAVAILABLE)
+func GRPCClientConfigFromViper(s *Standard) GRPCClientConfig {
+	// Bind environment variables
+	bindGRPCClientEnv(s, "grpc_client.", "GRPC_CLIENT_")
+
+	config := loadGRPCClientConfig(s, "grpc_client.", GRPCClientConfig{})
+
+	// Apply defaults
+	config.setDefaults()
+
+	return config
+}
+
+// GRPCClientConfigFromViperNamed creates a GRPCClientConfig for a named
+// upstream.
+//
+// Values are read from grpc_clients.<name>.* and GRPC_CLIENT_<NAME>_*
+// environment variables (e.g. GRPC_CLIENT_PAYMENTS_TARGET for "payments").
+// Any field that is not set for the instance falls back to the primary
+// grpc_client block, then to the defaults.
+func GRPCClientConfigFromViperNamed(s *Standard, name string) GRPCClientConfig {
+	bindGRPCClientEnv(s, "grpc_client.", "GRPC_CLIENT_")
+	primary := loadGRPCClientConfig(s, "grpc_client.", GRPCClientConfig{})
+
+	prefix := "grpc_clients." + name + "."
+	bindGRPCClientEnv(s, prefix, "GRPC_CLIENT_"+envName(name)+"_")
+
+	config := loadGRPCClientConfig(s, prefix, primary)
+	config.Name = name
+
+	// Apply defaults
+	config.setDefaults()
+
+	return config
+}
+
+// loadGRPCClientConfig reads the gRPC client keys under prefix, using the
+// values in fallback for any key that is not set.
+func loadGRPCClientConfig(s *Standard, prefix string, fallback GRPCClientConfig) GRPCClientConfig {
+	return GRPCClientConfig{
+		Target:                       s.stringOr(prefix+"target", fallback.Target),
+		KeepaliveTime:                s.durationOr(prefix+"keepalive_time", fallback.KeepaliveTime),
+		KeepaliveTimeout:             s.durationOr(prefix+"keepalive_timeout", fallback.KeepaliveTimeout),
+		KeepalivePermitWithoutStream: s.boolOr(prefix+"keepalive_permit_without_stream", fallback.KeepalivePermitWithoutStream),
+		LoadBalancingPolicy:          s.stringOr(prefix+"load_balancing_policy", fallback.LoadBalancingPolicy),
+		Timeout:                      s.durationOr(prefix+"timeout", fallback.Timeout),
+		MaxAttempts:                  s.intOr(prefix+"max_attempts", fallback.MaxAttempts),
+		InitialBackoff:               s.durationOr(prefix+"initial_backoff", fallback.InitialBackoff),
+		MaxBackoff:                   s.durationOr(prefix+"max_backoff", fallback.MaxBackoff),
+		BackoffMultiplier:            s.floatOr(prefix+"backoff_multiplier", fallback.BackoffMultiplier),
+		RetryableStatusCodes:         s.stringListOr(prefix+"retryable_status_codes", fallback.RetryableStatusCodes),
+	}
+}
+
+// bindGRPCClientEnv binds the gRPC client keys under prefix to environment
+// variables starting with envPrefix.
+func bindGRPCClientEnv(s *Standard, prefix, envPrefix string) {
+	_ = s.BindEnv(prefix+"target", envPrefix+"TARGET")
+	_ = s.BindEnv(prefix+"keepalive_time", envPrefix+"KEEPALIVE_TIME")
+	_ = s.BindEnv(prefix+"keepalive_timeout", envPrefix+"KEEPALIVE_TIMEOUT")
+	_ = s.BindEnv(prefix+"keepalive_permit_without_stream", envPrefix+"KEEPALIVE_PERMIT_WITHOUT_STREAM")
+	_ = s.BindEnv(prefix+"load_balancing_policy", envPrefix+"LOAD_BALANCING_POLICY")
+	_ = s.BindEnv(prefix+"timeout", envPrefix+"TIMEOUT")
+	_ = s.BindEnv(prefix+"max_attempts", envPrefix+"MAX_ATTEMPTS")
+	_ = s.BindEnv(prefix+"initial_backoff", envPrefix+"INITIAL_BACKOFF")
+	_ = s.BindEnv(prefix+"max_backoff", envPrefix+"MAX_BACKOFF")
+	_ = s.BindEnv(prefix+"backoff_multiplier", envPrefix+"BACKOFF_MULTIPLIER")
+	_ = s.BindEnv(prefix+"retryable_status_codes", envPrefix+"RETRYABLE_STATUS_CODES")
+}
+
+// setDefaults sets default values for optional fields
+func (c *GRPCClientConfig) setDefaults() {
+	if c.KeepaliveTimeout == 0 {
+		c.KeepaliveTimeout = 20 * time.Second
+	}
+	if c.LoadBalancingPolicy == "" {
+		c.LoadBalancingPolicy = GRPCPickFirst
+	}
+	if c.MaxAttempts == 0 {
+		c.MaxAttempts = 1
+	}
+	if c.InitialBackoff == 0 {
+		c.InitialBackoff = 100 * time.Millisecond
+	}
+	if c.MaxBackoff == 0 {
+		c.MaxBackoff = time.Second
+	}
+	if c.BackoffMultiplier == 0 {
+		c.BackoffMultiplier = 2.0
+	}
+	if len(c.RetryableStatusCodes) == 0 {
+		c.RetryableStatusCodes = []string{"UNAVAILABLE"}
+	}
+}
+
+// grpcClientEnvSuffixes are the environment variables of the primary
+// grpc_client block after the GRPC_CLIENT_ prefix. A named client whose
+// GRPC_CLIENT_<NAME>_ prefix starts one of them would be ambiguous, so such
+// names are reserved.
+var grpcClientEnvSuffixes = []string{
+	"TARGET", "KEEPALIVE_TIME", "KEEPALIVE_TIMEOUT", "KEEPALIVE_PERMIT_WITHOUT_STREAM",
+	"LOAD_BALANCING_POLICY", "TIMEOUT", "MAX_ATTEMPTS", "INITIAL_BACKOFF", "MAX_BACKOFF",
+	"BACKOFF_MULTIPLIER", "RETRYABLE_STATUS_CODES",
+}
+
+// Validate validates the gRPC client configuration
+func (c *GRPCClientConfig) Validate() error {
+	if suffix := envPrefixClash(c.Name, grpcClientEnvSuffixes); c.Name != "" && suffix != "" {
+		return fmt.Errorf("grpc_clients.%s is a reserved client name: its GRPC_CLIENT_%s_* variables clash with GRPC_CLIENT_%s",
+			c.Name, envName(c.Name), suffix)
+	}
+	if err := ValidateRequired(c.key("target"), c.Target); err != nil {
+		return err
+	}
+	if c.KeepaliveTime > 0 && c.KeepaliveTime < 10*time.Second {
+		return fmt.Errorf("%s must be at least 10s, got %v", c.key("keepalive_time"), c.KeepaliveTime)
+	}
+	if err := ValidateDuration(c.key("keepalive_time"), c.KeepaliveTime); err != nil {
+		return err
+	}
+	if err := ValidateDuration(c.key("keepalive_timeout"), c.KeepaliveTimeout); err != nil {
+		return err
+	}
+	switch c.LoadBalancingPolicy {
+	case GRPCPickFirst, GRPCRoundRobin:
+	default:
+		return fmt.Errorf("%s must be one of: %v", c.key("load_balancing_policy"),
+			[]string{GRPCPickFirst, GRPCRoundRobin})
+	}
+	if err := ValidateDuration(c.key("timeout"), c.Timeout); err != nil {
+		return err
+	}
+
+	// gRPC caps retry attempts at 5, including the original call
+	if err := ValidateRange(c.key("max_attempts"), c.MaxAttempts, 1, 5); err != nil {
+		return err
+	}
+	if c.MaxAttempts == 1 {
+		return nil
+	}
+	if c.InitialBackoff <= 0 {
+		return fmt.Errorf("%s must be positive, got %v", c.key("initial_backoff"), c.InitialBackoff)
+	}
+	if c.MaxBackoff < c.InitialBackoff {
+		return fmt.Errorf("%s (%v) must be greater than or equal to initial_backoff (%v)",
+			c.key("max_backoff"), c.MaxBackoff, c.InitialBackoff)
+	}
+	if c.BackoffMultiplier <= 0 {
+		return fmt.Errorf("%s must be positive, got %v", c.key("backoff_multiplier"), c.BackoffMultiplier)
+	}
+	if len(c.RetryableStatusCodes) == 0 {
+		return fmt.Errorf("%s is required when max_attempts is greater than 1", c.key("retryable_status_codes"))
+	}
+	for _, code := range c.RetryableStatusCodes {
+		if !grpcStatusCodes[strings.ToUpper(code)] {
+			return fmt.Errorf("%s contains unknown status code %q", c.key("retryable_status_codes"), code)
+		}
+	}
+
+	return nil
+}
+
+// key returns the fully qualified config key for field, used in validation
+// errors so that named instances can be told apart.
+func (c *GRPCClientConfig) key(field string) string {
+	if c.Name == "" {
+		return "grpc_client." + field
+	}
+	return "grpc_clients." + c.Name + "." + field
+}
+
+// grpcServiceConfig is the JSON shape of a gRPC service config
+type grpcServiceConfig struct {
+	LoadBalancingConfig []map[string]struct{} `json:"loadBalancingConfig"`
+	MethodConfig        []grpcMethodConfig    `json:"methodConfig,omitempty"`
+}
+
+// grpcMethodConfig applies to every method of every service
+type grpcMethodConfig struct {
+	Name        []struct{}       `json:"name"`
+	Timeout     string           `json:"timeout,omitempty"`
+	RetryPolicy *grpcRetryPolicy `json:"retryPolicy,omitempty"`
+}
+
+// grpcRetryPolicy is the retryPolicy block of a method config
+type grpcRetryPolicy struct {
+	MaxAttempts          int      `json:"maxAttempts"`
+	InitialBackoff       string   `json:"initialBackoff"`
+	MaxBackoff           string   `json:"maxBackoff"`
+	BackoffMultiplier    float64  `json:"backoffMultiplier"`
+	RetryableStatusCodes []string `json:"retryableStatusCodes"`
+}
+
+// ServiceConfigJSON returns the standard gRPC service config JSON carrying the
+// load balancing policy, default timeout and retry policy, for
+// grpc.WithDefaultServiceConfig. The timeout and retry policy apply to all
+// methods.
+func (c *GRPCClientConfig) ServiceConfigJSON() (string, error) {
+	if err := c.Validate(); err != nil {
+		return "", err
+	}
+
+	sc := grpcServiceConfig{
+		LoadBalancingConfig: []map[string]struct{}{{c.LoadBalancingPolicy: {}}},
+	}
+
+	method := grpcMethodConfig{Name: []struct{}{{}}}
+	if c.Timeout > 0 {
+		method.Timeout = protoDuration(c.Timeout)
+	}
+	if c.MaxAttempts > 1 {
+		codes := make([]string, len(c.RetryableStatusCodes))
+		for i, code := range c.RetryableStatusCodes {
+			codes[i] = strings.ToUpper(code)
+		}
+		method.RetryPolicy = &grpcRetryPolicy{
+			MaxAttempts:          c.MaxAttempts,
+			InitialBackoff:       protoDuration(c.InitialBackoff),
+			MaxBackoff:           protoDuration(c.MaxBackoff),
+			BackoffMultiplier:    c.BackoffMultiplier,
+			RetryableStatusCodes: codes,
+		}
+	}
+	if method.Timeout != "" || method.RetryPolicy != nil {
+		sc.MethodConfig = []grpcMethodConfig{method}
+	}
+
+	out, err := json.Marshal(sc)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode gRPC service config: %w", err)
+	}
+	return string(out), nil
+}
+
+// protoDuration formats d as a protobuf JSON duration, e.g. "0.1s"
+func protoDuration(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64) + "s"
+}
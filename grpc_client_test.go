@@ -0,0 +1,195 @@
+package config_test
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	config "github.com/JohnPlummer/jp-go-config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGRPCClientConfigFromViper(t *testing.T) {
+	t.Run("uses defaults when no config provided", func(t *testing.T) {
+		std, err := config.NewStandard()
+		require.NoError(t, err)
+
+		cfg := config.GRPCClientConfigFromViper(std)
+
+		assert.Empty(t, cfg.Target)
+		assert.Equal(t, config.GRPCPickFirst, cfg.LoadBalancingPolicy)
+		assert.Equal(t, 1, cfg.MaxAttempts)
+		assert.Equal(t, 100*time.Millisecond, cfg.InitialBackoff)
+		assert.Equal(t, []string{"UNAVAILABLE"}, cfg.RetryableStatusCodes)
+
+		err = cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "grpc_client.target is required")
+	})
+
+	t.Run("loads from environment variables", func(t *testing.T) {
+		os.Setenv("GRPC_CLIENT_TARGET", "dns:///payments:50051")
+		os.Setenv("GRPC_CLIENT_KEEPALIVE_TIME", "30s")
+		os.Setenv("GRPC_CLIENT_LOAD_BALANCING_POLICY", "round_robin")
+		os.Setenv("GRPC_CLIENT_MAX_ATTEMPTS", "4")
+		os.Setenv("GRPC_CLIENT_BACKOFF_MULTIPLIER", "1.5")
+		os.Setenv("GRPC_CLIENT_RETRYABLE_STATUS_CODES", "UNAVAILABLE,RESOURCE_EXHAUSTED")
+		defer func() {
+			os.Unsetenv("GRPC_CLIENT_TARGET")
+			os.Unsetenv("GRPC_CLIENT_KEEPALIVE_TIME")
+			os.Unsetenv("GRPC_CLIENT_LOAD_BALANCING_POLICY")
+			os.Unsetenv("GRPC_CLIENT_MAX_ATTEMPTS")
+			os.Unsetenv("GRPC_CLIENT_BACKOFF_MULTIPLIER")
+			os.Unsetenv("GRPC_CLIENT_RETRYABLE_STATUS_CODES")
+		}()
+
+		std, err := config.NewStandard()
+		require.NoError(t, err)
+
+		cfg := config.GRPCClientConfigFromViper(std)
+
+		assert.Equal(t, "dns:///payments:50051", cfg.Target)
+		assert.Equal(t, 30*time.Second, cfg.KeepaliveTime)
+		assert.Equal(t, config.GRPCRoundRobin, cfg.LoadBalancingPolicy)
+		assert.Equal(t, 4, cfg.MaxAttempts)
+		assert.InDelta(t, 1.5, cfg.BackoffMultiplier, 0.001)
+		assert.Equal(t, []string{"UNAVAILABLE", "RESOURCE_EXHAUSTED"}, cfg.RetryableStatusCodes)
+		require.NoError(t, cfg.Validate())
+	})
+}
+
+func TestGRPCClientConfigFromViperNamed(t *testing.T) {
+	os.Setenv("GRPC_CLIENT_MAX_ATTEMPTS", "3")
+	os.Setenv("GRPC_CLIENT_LEDGER_TARGET", "dns:///ledger:50051")
+	defer func() {
+		os.Unsetenv("GRPC_CLIENT_MAX_ATTEMPTS")
+		os.Unsetenv("GRPC_CLIENT_LEDGER_TARGET")
+	}()
+
+	std, err := config.NewStandard()
+	require.NoError(t, err)
+	std.Set("grpc_clients.ledger.load_balancing_policy", "least_request")
+
+	cfg := config.GRPCClientConfigFromViperNamed(std, "ledger")
+
+	assert.Equal(t, "ledger", cfg.Name)
+	assert.Equal(t, "dns:///ledger:50051", cfg.Target)
+	assert.Equal(t, 3, cfg.MaxAttempts, "falls back to the primary block")
+
+	err = cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "grpc_clients.ledger.load_balancing_policy")
+
+	t.Run("rejects reserved client names", func(t *testing.T) {
+		for _, name := range []string{"keepalive", "max", "backoff"} {
+			cfg := config.GRPCClientConfigFromViperNamed(std, name)
+
+			err := cfg.Validate()
+			require.Error(t, err, name)
+			assert.Contains(t, err.Error(), "grpc_clients."+name+" is a reserved client name")
+		}
+	})
+}
+
+func TestGRPCClientConfig_Validate(t *testing.T) {
+	valid := func() config.GRPCClientConfig {
+		return config.GRPCClientConfig{
+			Target:               "dns:///payments:50051",
+			KeepaliveTimeout:     20 * time.Second,
+			LoadBalancingPolicy:  config.GRPCPickFirst,
+			MaxAttempts:          3,
+			InitialBackoff:       100 * time.Millisecond,
+			MaxBackoff:           time.Second,
+			BackoffMultiplier:    2,
+			RetryableStatusCodes: []string{"UNAVAILABLE"},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(cfg *config.GRPCClientConfig)
+		wantErr string
+	}{
+		{name: "valid config passes", modify: func(*config.GRPCClientConfig) {}},
+		{name: "short keepalive fails", modify: func(cfg *config.GRPCClientConfig) { cfg.KeepaliveTime = time.Second }, wantErr: "grpc_client.keepalive_time"},
+		{name: "too many attempts fails", modify: func(cfg *config.GRPCClientConfig) { cfg.MaxAttempts = 6 }, wantErr: "grpc_client.max_attempts"},
+		{name: "max backoff below initial fails", modify: func(cfg *config.GRPCClientConfig) { cfg.MaxBackoff = 10 * time.Millisecond }, wantErr: "grpc_client.max_backoff"},
+		{name: "unknown status code fails", modify: func(cfg *config.GRPCClientConfig) { cfg.RetryableStatusCodes = []string{"TEAPOT"} }, wantErr: "TEAPOT"},
+		{name: "lowercase status code passes", modify: func(cfg *config.GRPCClientConfig) { cfg.RetryableStatusCodes = []string{"unavailable"} }},
+		{name: "retry settings ignored without retries", modify: func(cfg *config.GRPCClientConfig) {
+			cfg.MaxAttempts = 1
+			cfg.RetryableStatusCodes = nil
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid()
+			tt.modify(&cfg)
+
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestGRPCClientConfig_ServiceConfigJSON(t *testing.T) {
+	t.Run("includes retry policy and timeout", func(t *testing.T) {
+		cfg := config.GRPCClientConfig{
+			Target:               "dns:///payments:50051",
+			LoadBalancingPolicy:  config.GRPCRoundRobin,
+			Timeout:              5 * time.Second,
+			MaxAttempts:          3,
+			InitialBackoff:       100 * time.Millisecond,
+			MaxBackoff:           time.Second,
+			BackoffMultiplier:    2,
+			RetryableStatusCodes: []string{"unavailable"},
+		}
+
+		out, err := cfg.ServiceConfigJSON()
+		require.NoError(t, err)
+		assert.JSONEq(t, `{
+			"loadBalancingConfig": [{"round_robin": {}}],
+			"methodConfig": [{
+				"name": [{}],
+				"timeout": "5s",
+				"retryPolicy": {
+					"maxAttempts": 3,
+					"initialBackoff": "0.1s",
+					"maxBackoff": "1s",
+					"backoffMultiplier": 2,
+					"retryableStatusCodes": ["UNAVAILABLE"]
+				}
+			}]
+		}`, out)
+	})
+
+	t.Run("omits method config without retries or timeout", func(t *testing.T) {
+		cfg := config.GRPCClientConfig{
+			Target:              "localhost:50051",
+			LoadBalancingPolicy: config.GRPCPickFirst,
+			MaxAttempts:         1,
+		}
+
+		out, err := cfg.ServiceConfigJSON()
+		require.NoError(t, err)
+
+		var decoded map[string]any
+		require.NoError(t, json.Unmarshal([]byte(out), &decoded))
+		assert.NotContains(t, decoded, "methodConfig")
+		assert.Equal(t, []any{map[string]any{"pick_first": map[string]any{}}}, decoded["loadBalancingConfig"])
+	})
+
+	t.Run("fails for invalid config", func(t *testing.T) {
+		cfg := config.GRPCClientConfig{}
+		_, err := cfg.ServiceConfigJSON()
+		require.Error(t, err)
+	})
+}
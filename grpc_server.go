@@ -0,0 +1,240 @@
+package config
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+)
+
+// GRPCServerConfig holds gRPC server configuration. It only produces standard
+// library values, so this package does not depend on grpc; map the fields to
+// grpc.ServerOption values in the service:
+//
+//	grpc.NewServer(
+//		grpc.KeepaliveParams(keepalive.ServerParameters{
+//			Time:                  cfg.KeepaliveTime,
+//			Timeout:               cfg.KeepaliveTimeout,
+//			MaxConnectionIdle:     cfg.MaxConnectionIdle,
+//			MaxConnectionAge:      cfg.MaxConnectionAge,
+//			MaxConnectionAgeGrace: cfg.MaxConnectionAgeGrace,
+//		}),
+//		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+//			MinTime:             cfg.KeepaliveMinTime,
+//			PermitWithoutStream: cfg.KeepalivePermitWithoutStream,
+//		}),
+//		grpc.MaxRecvMsgSize(recvBytes),
+//		grpc.MaxSendMsgSize(sendBytes),
+//		grpc.MaxConcurrentStreams(cfg.MaxConcurrentStreams),
+//		grpc.Creds(credentials.NewTLS(tlsConfig)),
+//	)
+type GRPCServerConfig struct {
+	Host string `mapstructure:"host"`
+	Port int    `mapstructure:"port"`
+
+	// Keepalive pings sent by the server
+	KeepaliveTime    time.Duration `mapstructure:"keepalive_time"`
+	KeepaliveTimeout time.Duration `mapstructure:"keepalive_timeout"`
+
+	// Connection lifetime limits. Zero means no limit.
+	MaxConnectionIdle     time.Duration `mapstructure:"max_connection_idle"`
+	MaxConnectionAge      time.Duration `mapstructure:"max_connection_age"`
+	MaxConnectionAgeGrace time.Duration `mapstructure:"max_connection_age_grace"`
+
+	// Keepalive enforcement: clients pinging more often than KeepaliveMinTime,
+	// or without active streams unless permitted, are disconnected
+	KeepaliveMinTime             time.Duration `mapstructure:"keepalive_min_time"`
+	KeepalivePermitWithoutStream bool          `mapstructure:"keepalive_permit_without_stream"`
+
+	// Message size limits such as "4MB"
+	MaxRecvMsgSize string `mapstructure:"max_recv_msg_size"`
+	MaxSendMsgSize string `mapstructure:"max_send_msg_size"`
+
+	// MaxConcurrentStreams limits streams per connection (0: unlimited)
+	MaxConcurrentStreams uint32 `mapstructure:"max_concurrent_streams"`
+
+	// TLS terminates TLS when a certificate and key are configured
+	TLS ServerTLSConfig `mapstructure:"tls"`
+}
+
+// GRPCServerConfigFromViper creates a GRPCServerConfig from a Standard config loader.
+//
+// Environment variable mappings:
+//   - GRPC_SERVER_HOST -> host (default: localhost)
+//   - GRPC_SERVER_PORT -> port (default: 50051)
+//   - GRPC_SERVER_KEEPALIVE_TIME -> keepalive_time (default: 2h)
+//   - GRPC_SERVER_KEEPALIVE_TIMEOUT -> keepalive_timeout (default: 20s)
+//   - GRPC_SERVER_MAX_CONNECTION_IDLE -> max_connection_idle
+//   - GRPC_SERVER_MAX_CONNECTION_AGE -> max_connection_age
+//   - GRPC_SERVER_MAX_CONNECTION_AGE_GRACE -> max_connection_age_grace
+//   - GRPC_SERVER_KEEPALIVE_MIN_TIME -> keepalive_min_time (default: 5m)
+//   - GRPC_SERVER_KEEPALIVE_PERMIT_WITHOUT_STREAM -> keepalive_permit_without_stream (default: false)
+//   - GRPC_SERVER_MAX_RECV_MSG_SIZE -> max_recv_msg_size (default: 4MB)
+//   - GRPC_SERVER_MAX_SEND_MSG_SIZE -> max_send_msg_size (default: 4MB)
+//   - GRPC_SERVER_MAX_CONCURRENT_STREAMS -> max_concurrent_streams (default: unlimited)
+//   - GRPC_SERVER_TLS_CERT_FILE -> tls.cert_file
+//   - GRPC_SERVER_TLS_KEY_FILE -> tls.key_file
+//   - GRPC_SERVER_TLS_CLIENT_CA_FILE -> tls.client_ca_file
+//   - GRPC_SERVER_TLS_MIN_VERSION -> tls.min_version (default: 1.2)
+//   - GRPC_SERVER_TLS_CIPHER_SUITES -> tls.cipher_suites (comma-separated)
+//   - GRPC_SERVER_TLS_CLIENT_AUTH -> tls.client_auth (default: none)
+func GRPCServerConfigFromViper(s *Standard) GRPCServerConfig {
+	// Bind environment variables
+	_ = s.BindEnv("grpc_server.host", "GRPC_SERVER_HOST")
+	_ = s.BindEnv("grpc_server.port", "GRPC_SERVER_PORT")
+	_ = s.BindEnv("grpc_server.keepalive_time", "GRPC_SERVER_KEEPALIVE_TIME")
+	_ = s.BindEnv("grpc_server.keepalive_timeout", "GRPC_SERVER_KEEPALIVE_TIMEOUT")
+	_ = s.BindEnv("grpc_server.max_connection_idle", "GRPC_SERVER_MAX_CONNECTION_IDLE")
+	_ = s.BindEnv("grpc_server.max_connection_age", "GRPC_SERVER_MAX_CONNECTION_AGE")
+	_ = s.BindEnv("grpc_server.max_connection_age_grace", "GRPC_SERVER_MAX_CONNECTION_AGE_GRACE")
+	_ = s.BindEnv("grpc_server.keepalive_min_time", "GRPC_SERVER_KEEPALIVE_MIN_TIME")
+	_ = s.BindEnv("grpc_server.keepalive_permit_without_stream", "GRPC_SERVER_KEEPALIVE_PERMIT_WITHOUT_STREAM")
+	_ = s.BindEnv("grpc_server.max_recv_msg_size", "GRPC_SERVER_MAX_RECV_MSG_SIZE")
+	_ = s.BindEnv("grpc_server.max_send_msg_size", "GRPC_SERVER_MAX_SEND_MSG_SIZE")
+	_ = s.BindEnv("grpc_server.max_concurrent_streams", "GRPC_SERVER_MAX_CONCURRENT_STREAMS")
+	_ = s.BindEnv("grpc_server.tls.cert_file", "GRPC_SERVER_TLS_CERT_FILE")
+	_ = s.BindEnv("grpc_server.tls.key_file", "GRPC_SERVER_TLS_KEY_FILE")
+	_ = s.BindEnv("grpc_server.tls.client_ca_file", "GRPC_SERVER_TLS_CLIENT_CA_FILE")
+	_ = s.BindEnv("grpc_server.tls.min_version", "GRPC_SERVER_TLS_MIN_VERSION")
+	_ = s.BindEnv("grpc_server.tls.cipher_suites", "GRPC_SERVER_TLS_CIPHER_SUITES")
+	_ = s.BindEnv("grpc_server.tls.client_auth", "GRPC_SERVER_TLS_CLIENT_AUTH")
+
+	config := GRPCServerConfig{
+		Host:                         s.GetString("grpc_server.host"),
+		Port:                         s.GetInt("grpc_server.port"),
+		KeepaliveTime:                s.viper.GetDuration("grpc_server.keepalive_time"),
+		KeepaliveTimeout:             s.viper.GetDuration("grpc_server.keepalive_timeout"),
+		MaxConnectionIdle:            s.viper.GetDuration("grpc_server.max_connection_idle"),
+		MaxConnectionAge:             s.viper.GetDuration("grpc_server.max_connection_age"),
+		MaxConnectionAgeGrace:        s.viper.GetDuration("grpc_server.max_connection_age_grace"),
+		KeepaliveMinTime:             s.viper.GetDuration("grpc_server.keepalive_min_time"),
+		KeepalivePermitWithoutStream: s.GetBool("grpc_server.keepalive_permit_without_stream"),
+		MaxRecvMsgSize:               s.GetString("grpc_server.max_recv_msg_size"),
+		MaxSendMsgSize:               s.GetString("grpc_server.max_send_msg_size"),
+		MaxConcurrentStreams:         s.viper.GetUint32("grpc_server.max_concurrent_streams"),
+		TLS:                          loadServerTLSConfig(s, "grpc_server.tls."),
+	}
+
+	// Apply defaults
+	config.setDefaults()
+
+	return config
+}
+
+// setDefaults sets default values for optional fields, matching grpc-go's own
+// defaults where it has them
+func (c *GRPCServerConfig) setDefaults() {
+	if c.Host == "" {
+		c.Host = "localhost"
+	}
+	if c.Port == 0 {
+		c.Port = 50051
+	}
+	if c.KeepaliveTime == 0 {
+		c.KeepaliveTime = 2 * time.Hour
+	}
+	if c.KeepaliveTimeout == 0 {
+		c.KeepaliveTimeout = 20 * time.Second
+	}
+	if c.KeepaliveMinTime == 0 {
+		c.KeepaliveMinTime = 5 * time.Minute
+	}
+	if c.MaxRecvMsgSize == "" {
+		c.MaxRecvMsgSize = "4MB"
+	}
+	if c.MaxSendMsgSize == "" {
+		c.MaxSendMsgSize = "4MB"
+	}
+	c.TLS.setDefaults()
+}
+
+// Validate validates the gRPC server configuration
+func (c *GRPCServerConfig) Validate() error {
+	if err := ValidateRequired("grpc_server.host", c.Host); err != nil {
+		return err
+	}
+	if err := ValidatePort("grpc_server.port", c.Port); err != nil {
+		return err
+	}
+	if err := ValidateDuration("grpc_server.keepalive_time", c.KeepaliveTime); err != nil {
+		return err
+	}
+	if err := ValidateDuration("grpc_server.keepalive_timeout", c.KeepaliveTimeout); err != nil {
+		return err
+	}
+	if err := ValidateDuration("grpc_server.max_connection_idle", c.MaxConnectionIdle); err != nil {
+		return err
+	}
+	if err := ValidateDuration("grpc_server.max_connection_age", c.MaxConnectionAge); err != nil {
+		return err
+	}
+	if err := ValidateDuration("grpc_server.max_connection_age_grace", c.MaxConnectionAgeGrace); err != nil {
+		return err
+	}
+	if err := ValidateDuration("grpc_server.keepalive_min_time", c.KeepaliveMinTime); err != nil {
+		return err
+	}
+	if c.KeepaliveTime > 0 && c.KeepaliveTime < time.Second {
+		return fmt.Errorf("grpc_server.keepalive_time must be at least 1s, got %v", c.KeepaliveTime)
+	}
+	if _, err := c.MaxRecvMsgBytes(); err != nil {
+		return fmt.Errorf("grpc_server.max_recv_msg_size: %w", err)
+	}
+	if _, err := c.MaxSendMsgBytes(); err != nil {
+		return fmt.Errorf("grpc_server.max_send_msg_size: %w", err)
+	}
+	if err := c.TLS.validate("grpc_server.tls"); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Address returns the server address in host:port format
+func (c *GRPCServerConfig) Address() string {
+	return net.JoinHostPort(c.Host, strconv.Itoa(c.Port))
+}
+
+// MaxRecvMsgBytes returns MaxRecvMsgSize in bytes, for grpc.MaxRecvMsgSize
+func (c *GRPCServerConfig) MaxRecvMsgBytes() (int, error) {
+	return messageSizeBytes(c.MaxRecvMsgSize)
+}
+
+// MaxSendMsgBytes returns MaxSendMsgSize in bytes, for grpc.MaxSendMsgSize
+func (c *GRPCServerConfig) MaxSendMsgBytes() (int, error) {
+	return messageSizeBytes(c.MaxSendMsgSize)
+}
+
+// defaultGRPCMessageSize is grpc-go's default maximum message size
+const defaultGRPCMessageSize = 4 << 20
+
+// messageSizeBytes parses a positive message size that fits in an int32, the
+// largest size gRPC accepts. An empty size is the gRPC default of 4MB.
+func messageSizeBytes(size string) (int, error) {
+	if size == "" {
+		return defaultGRPCMessageSize, nil
+	}
+	n, err := ParseByteSize(size)
+	if err != nil {
+		return 0, err
+	}
+	if n <= 0 || n > 1<<31-1 {
+		return 0, fmt.Errorf("must be between 1 byte and 2GB, got %q", size)
+	}
+	return int(n), nil
+}
+
+// TLSConfig builds a *tls.Config for credentials.NewTLS from the TLS
+// settings. Certificates are reloaded when they change on disk, as with
+// ServerConfig.TLSConfig.
+func (c *GRPCServerConfig) TLSConfig() (*tls.Config, error) {
+	if !c.TLS.Enabled() {
+		return nil, errors.New("grpc_server.tls is not enabled: cert_file and key_file are required")
+	}
+	if err := c.TLS.validate("grpc_server.tls"); err != nil {
+		return nil, err
+	}
+	return newTLSReloader(c.TLS).serverConfig()
+}
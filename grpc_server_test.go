@@ -0,0 +1,139 @@
+package config_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	config "github.com/JohnPlummer/jp-go-config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGRPCServerConfigFromViper(t *testing.T) {
+	t.Run("uses defaults when no config provided", func(t *testing.T) {
+		std, err := config.NewStandard()
+		require.NoError(t, err)
+
+		cfg := config.GRPCServerConfigFromViper(std)
+
+		assert.Equal(t, "localhost:50051", cfg.Address())
+		assert.Equal(t, 2*time.Hour, cfg.KeepaliveTime)
+		assert.Equal(t, 20*time.Second, cfg.KeepaliveTimeout)
+		assert.Equal(t, 5*time.Minute, cfg.KeepaliveMinTime)
+		assert.Zero(t, cfg.MaxConcurrentStreams)
+		assert.False(t, cfg.TLS.Enabled())
+		require.NoError(t, cfg.Validate())
+
+		recv, err := cfg.MaxRecvMsgBytes()
+		require.NoError(t, err)
+		assert.Equal(t, 4<<20, recv)
+	})
+
+	t.Run("loads from environment variables", func(t *testing.T) {
+		os.Setenv("GRPC_SERVER_PORT", "6000")
+		os.Setenv("GRPC_SERVER_KEEPALIVE_TIME", "1m")
+		os.Setenv("GRPC_SERVER_MAX_CONNECTION_AGE", "30m")
+		os.Setenv("GRPC_SERVER_KEEPALIVE_MIN_TIME", "30s")
+		os.Setenv("GRPC_SERVER_KEEPALIVE_PERMIT_WITHOUT_STREAM", "true")
+		os.Setenv("GRPC_SERVER_MAX_RECV_MSG_SIZE", "16MB")
+		os.Setenv("GRPC_SERVER_MAX_CONCURRENT_STREAMS", "250")
+		defer func() {
+			os.Unsetenv("GRPC_SERVER_PORT")
+			os.Unsetenv("GRPC_SERVER_KEEPALIVE_TIME")
+			os.Unsetenv("GRPC_SERVER_MAX_CONNECTION_AGE")
+			os.Unsetenv("GRPC_SERVER_KEEPALIVE_MIN_TIME")
+			os.Unsetenv("GRPC_SERVER_KEEPALIVE_PERMIT_WITHOUT_STREAM")
+			os.Unsetenv("GRPC_SERVER_MAX_RECV_MSG_SIZE")
+			os.Unsetenv("GRPC_SERVER_MAX_CONCURRENT_STREAMS")
+		}()
+
+		std, err := config.NewStandard()
+		require.NoError(t, err)
+
+		cfg := config.GRPCServerConfigFromViper(std)
+
+		assert.Equal(t, 6000, cfg.Port)
+		assert.Equal(t, time.Minute, cfg.KeepaliveTime)
+		assert.Equal(t, 30*time.Minute, cfg.MaxConnectionAge)
+		assert.Equal(t, 30*time.Second, cfg.KeepaliveMinTime)
+		assert.True(t, cfg.KeepalivePermitWithoutStream)
+		assert.Equal(t, uint32(250), cfg.MaxConcurrentStreams)
+
+		recv, err := cfg.MaxRecvMsgBytes()
+		require.NoError(t, err)
+		assert.Equal(t, 16<<20, recv)
+	})
+}
+
+func TestGRPCServerConfig_Validate(t *testing.T) {
+	valid := func() config.GRPCServerConfig {
+		return config.GRPCServerConfig{
+			Host:             "localhost",
+			Port:             50051,
+			KeepaliveTime:    2 * time.Hour,
+			KeepaliveTimeout: 20 * time.Second,
+			KeepaliveMinTime: 5 * time.Minute,
+		}
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(cfg *config.GRPCServerConfig)
+		wantErr string
+	}{
+		{name: "valid config passes", modify: func(*config.GRPCServerConfig) {}},
+		{name: "invalid port fails", modify: func(cfg *config.GRPCServerConfig) { cfg.Port = 0 }, wantErr: "grpc_server.port"},
+		{name: "sub-second keepalive fails", modify: func(cfg *config.GRPCServerConfig) { cfg.KeepaliveTime = 500 * time.Millisecond }, wantErr: "grpc_server.keepalive_time"},
+		{name: "negative connection age fails", modify: func(cfg *config.GRPCServerConfig) { cfg.MaxConnectionAge = -time.Second }, wantErr: "grpc_server.max_connection_age"},
+		{name: "invalid message size fails", modify: func(cfg *config.GRPCServerConfig) { cfg.MaxSendMsgSize = "huge" }, wantErr: "grpc_server.max_send_msg_size"},
+		{name: "oversized message size fails", modify: func(cfg *config.GRPCServerConfig) { cfg.MaxRecvMsgSize = "4GB" }, wantErr: "grpc_server.max_recv_msg_size"},
+		{name: "missing key file fails", modify: func(cfg *config.GRPCServerConfig) { cfg.TLS.CertFile = "server.crt" }, wantErr: "grpc_server.tls.key_file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid()
+			tt.modify(&cfg)
+
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestGRPCServerConfig_TLSConfig(t *testing.T) {
+	t.Run("fails when TLS is not configured", func(t *testing.T) {
+		cfg := config.GRPCServerConfig{}
+		_, err := cfg.TLSConfig()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "grpc_server.tls")
+	})
+
+	t.Run("builds a mutual TLS config", func(t *testing.T) {
+		dir := t.TempDir()
+		ca := generateCert(t, dir, "ca", 1, nil)
+		server := generateCert(t, dir, "server", 2, ca)
+
+		cfg := config.GRPCServerConfig{TLS: config.ServerTLSConfig{
+			CertFile:     server.certFile,
+			KeyFile:      server.keyFile,
+			ClientCAFile: ca.certFile,
+			ClientAuth:   "require_and_verify",
+		}}
+
+		tlsConfig, err := cfg.TLSConfig()
+		require.NoError(t, err)
+		require.NotNil(t, tlsConfig.GetConfigForClient)
+
+		current, err := tlsConfig.GetConfigForClient(nil)
+		require.NoError(t, err)
+		assert.NotNil(t, current.ClientCAs)
+		assert.Len(t, current.Certificates, 1)
+	})
+}
@@ -0,0 +1,140 @@
+package config
+
+import (
+	"context"
+	"math"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Latency samples kept by a Hedger to compute HedgePercentile
+const (
+	hedgeSampleSize = 100
+	hedgeMinSamples = 10
+)
+
+// Hedger runs hedged calls: when a call has not finished after the hedge
+// delay, another attempt is started, up to MaxHedges extra attempts, and the
+// first success wins. The delay is the HedgePercentile latency of recent
+// successful calls, or HedgeDelay until enough calls have been seen or when
+// no percentile is configured. It is safe for concurrent use.
+type Hedger struct {
+	maxHedges  int
+	delay      time.Duration
+	percentile float64
+
+	mu      sync.Mutex
+	samples []time.Duration
+	next    int
+}
+
+// NewHedger returns a Hedger using MaxHedges, HedgeDelay and HedgePercentile
+// from c. With MaxHedges unset, calls run once without hedging.
+func (c *ResilienceConfig) NewHedger() *Hedger {
+	return &Hedger{
+		maxHedges:  c.MaxHedges,
+		delay:      c.HedgeDelay,
+		percentile: c.HedgePercentile,
+		samples:    make([]time.Duration, 0, hedgeSampleSize),
+	}
+}
+
+// Delay returns the current hedge delay
+func (h *Hedger) Delay() time.Duration {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.percentile == 0 || len(h.samples) < hedgeMinSamples {
+		return h.delay
+	}
+	sorted := slices.Clone(h.samples)
+	slices.Sort(sorted)
+	index := int(math.Ceil(h.percentile*float64(len(sorted)))) - 1
+	return sorted[max(index, 0)]
+}
+
+// observe records the latency of a successful attempt
+func (h *Hedger) observe(latency time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(h.samples) < hedgeSampleSize {
+		h.samples = append(h.samples, latency)
+		return
+	}
+	h.samples[h.next] = latency
+	h.next = (h.next + 1) % hedgeSampleSize
+}
+
+// hedgeResult is the outcome of one attempt
+type hedgeResult[T any] struct {
+	value T
+	err   error
+}
+
+// Hedge calls fn, starting another attempt each time the hedge delay passes
+// without a result, up to h's MaxHedges extra attempts. An attempt that fails
+// starts the next one straight away. Hedge returns the first successful
+// result and cancels the context passed to the other attempts; if every
+// attempt fails it returns the last error. fn must be safe to run
+// concurrently, so only hedge idempotent calls.
+func Hedge[T any](ctx context.Context, h *Hedger, fn func(ctx context.Context) (T, error)) (T, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan hedgeResult[T], h.maxHedges+1)
+	attempts, pending := 0, 0
+	launch := func() {
+		attempts++
+		pending++
+		go func() {
+			start := time.Now()
+			value, err := fn(ctx)
+			if err == nil {
+				h.observe(time.Since(start))
+			}
+			results <- hedgeResult[T]{value: value, err: err}
+		}()
+	}
+
+	delay := h.Delay()
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	// rearm starts the hedge delay again while another hedge is allowed
+	rearm := func() {
+		if attempts <= h.maxHedges && delay > 0 {
+			timer.Reset(delay)
+		} else {
+			timer.Stop()
+		}
+	}
+
+	launch()
+	rearm()
+	for {
+		select {
+		case result := <-results:
+			pending--
+			if result.err == nil {
+				return result.value, nil
+			}
+			if attempts > h.maxHedges {
+				if pending == 0 {
+					return result.value, result.err
+				}
+				continue
+			}
+			launch()
+			rearm()
+		case <-timer.C:
+			if attempts <= h.maxHedges {
+				launch()
+			}
+			rearm()
+		case <-ctx.Done():
+			var zero T
+			return zero, ctx.Err()
+		}
+	}
+}
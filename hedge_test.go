@@ -0,0 +1,120 @@
+package config_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	config "github.com/JohnPlummer/jp-go-config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHedge(t *testing.T) {
+	t.Run("hedges slow calls and returns the first success", func(t *testing.T) {
+		cfg := config.ResilienceConfig{MaxHedges: 1, HedgeDelay: 10 * time.Millisecond}
+		hedger := cfg.NewHedger()
+		var calls atomic.Int32
+
+		value, err := config.Hedge(context.Background(), hedger, func(ctx context.Context) (int, error) {
+			n := calls.Add(1)
+			if n == 1 {
+				// The first attempt hangs until the hedge wins
+				<-ctx.Done()
+				return 0, ctx.Err()
+			}
+			return int(n), nil
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, value)
+		assert.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("does not hedge fast calls", func(t *testing.T) {
+		cfg := config.ResilienceConfig{MaxHedges: 2, HedgeDelay: 50 * time.Millisecond}
+		hedger := cfg.NewHedger()
+		var calls atomic.Int32
+
+		_, err := config.Hedge(context.Background(), hedger, func(context.Context) (string, error) {
+			calls.Add(1)
+			return "ok", nil
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("returns the last error when every attempt fails", func(t *testing.T) {
+		cfg := config.ResilienceConfig{MaxHedges: 2, HedgeDelay: time.Second}
+		hedger := cfg.NewHedger()
+		var calls atomic.Int32
+		errBoom := errors.New("boom")
+
+		_, err := config.Hedge(context.Background(), hedger, func(context.Context) (int, error) {
+			calls.Add(1)
+			return 0, errBoom
+		})
+
+		assert.ErrorIs(t, err, errBoom)
+		assert.Equal(t, int32(3), calls.Load())
+	})
+
+	t.Run("a fast failure does not exceed MaxHedges", func(t *testing.T) {
+		cfg := config.ResilienceConfig{MaxHedges: 1, HedgeDelay: 50 * time.Millisecond}
+		hedger := cfg.NewHedger()
+		var calls atomic.Int32
+
+		value, err := config.Hedge(context.Background(), hedger, func(ctx context.Context) (int, error) {
+			n := calls.Add(1)
+			if n == 1 {
+				return 0, errors.New("boom")
+			}
+			// The replacement attempt outlasts the hedge delay
+			select {
+			case <-time.After(200 * time.Millisecond):
+				return int(n), nil
+			case <-ctx.Done():
+				return 0, ctx.Err()
+			}
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, value)
+		assert.Equal(t, int32(cfg.MaxHedges+1), calls.Load())
+	})
+
+	t.Run("runs once without hedging configured", func(t *testing.T) {
+		cfg := config.ResilienceConfig{}
+		hedger := cfg.NewHedger()
+		var calls atomic.Int32
+
+		_, err := config.Hedge(context.Background(), hedger, func(context.Context) (int, error) {
+			calls.Add(1)
+			time.Sleep(5 * time.Millisecond)
+			return 0, errors.New("boom")
+		})
+
+		require.Error(t, err)
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("derives the delay from the latency percentile", func(t *testing.T) {
+		cfg := config.ResilienceConfig{MaxHedges: 1, HedgeDelay: time.Second, HedgePercentile: 0.9}
+		hedger := cfg.NewHedger()
+		assert.Equal(t, time.Second, hedger.Delay(), "falls back to hedge_delay without samples")
+
+		for i := 0; i < 10; i++ {
+			_, err := config.Hedge(context.Background(), hedger, func(context.Context) (int, error) {
+				time.Sleep(time.Millisecond)
+				return 0, nil
+			})
+			require.NoError(t, err)
+		}
+
+		assert.Less(t, hedger.Delay(), time.Second)
+		assert.GreaterOrEqual(t, hedger.Delay(), time.Millisecond)
+	})
+}
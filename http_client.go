@@ -0,0 +1,336 @@
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// HTTPClientConfig holds outbound HTTP client configuration
+type HTTPClientConfig struct {
+	// Name identifies a named instance loaded with HTTPClientConfigFromViperNamed.
+	// It is empty for the primary client.
+	Name string `mapstructure:"-"`
+
+	// Timeout bounds a whole request including redirects and reading the body
+	Timeout               time.Duration `mapstructure:"timeout"`
+	DialTimeout           time.Duration `mapstructure:"dial_timeout"`
+	TLSHandshakeTimeout   time.Duration `mapstructure:"tls_handshake_timeout"`
+	ResponseHeaderTimeout time.Duration `mapstructure:"response_header_timeout"`
+	IdleConnTimeout       time.Duration `mapstructure:"idle_conn_timeout"`
+
+	// Connection pool settings
+	MaxIdleConns        int `mapstructure:"max_idle_conns"`
+	MaxIdleConnsPerHost int `mapstructure:"max_idle_conns_per_host"`
+	MaxConnsPerHost     int `mapstructure:"max_conns_per_host"`
+
+	// ProxyURL routes requests through a proxy. When empty, the standard
+	// HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables apply.
+	ProxyURL string `mapstructure:"proxy_url"`
+
+	// CAFile is a PEM bundle of root CAs trusted in addition to the system roots
+	CAFile string `mapstructure:"ca_file"`
+
+	// UserAgent is sent on requests that do not set their own User-Agent
+	UserAgent string `mapstructure:"user_agent"`
+}
+
+// HTTPClientConfigFromViper creates an HTTPClientConfig from a Standard config loader.
+//
+// Environment variable mappings:
+//   - HTTP_CLIENT_TIMEOUT -> timeout (default: 30s)
+//   - HTTP_CLIENT_DIAL_TIMEOUT -> dial_timeout (default: 5s)
+//   - HTTP_CLIENT_TLS_HANDSHAKE_TIMEOUT -> tls_handshake_timeout (default: 10s)
+//   - HTTP_CLIENT_RESPONSE_HEADER_TIMEOUT -> response_header_timeout
+//   - HTTP_CLIENT_IDLE_CONN_TIMEOUT -> idle_conn_timeout (default: 90s)
+//   - HTTP_CLIENT_MAX_IDLE_CONNS -> max_idle_conns (default: 100)
+//   - HTTP_CLIENT_MAX_IDLE_CONNS_PER_HOST -> max_idle_conns_per_host (default: 10)
+//   - HTTP_CLIENT_MAX_CONNS_PER_HOST -> max_conns_per_host (default: unlimited)
+//   - HTTP_CLIENT_PROXY_URL -> proxy_url
+//   - HTTP_CLIENT_CA_FILE -> ca_file
+//   - HTTP_CLIENT_USER_AGENT -> user_agent
+func HTTPClientConfigFromViper(s *Standard) HTTPClientConfig {
+	// Bind environment variables
+	bindHTTPClientEnv(s, "http_client.", "HTTP_CLIENT_")
+
+	config := loadHTTPClientConfig(s, "http_client.", HTTPClientConfig{})
+
+	// Apply defaults
+	config.setDefaults()
+
+	return config
+}
+
+// HTTPClientConfigFromViperNamed creates an HTTPClientConfig for a named
+// upstream, such as a payments API with its own timeouts and CA bundle.
+//
+// Values are read from http_clients.<name>.* and HTTP_CLIENT_<NAME>_*
+// environment variables (e.g. HTTP_CLIENT_PAYMENTS_TIMEOUT for "payments").
+// Any field that is not set for the instance falls back to the primary
+// http_client block, then to the defaults.
+func HTTPClientConfigFromViperNamed(s *Standard, name string) HTTPClientConfig {
+	bindHTTPClientEnv(s, "http_client.", "HTTP_CLIENT_")
+	primary := loadHTTPClientConfig(s, "http_client.", HTTPClientConfig{})
+
+	prefix := "http_clients." + name + "."
+	bindHTTPClientEnv(s, prefix, "HTTP_CLIENT_"+envName(name)+"_")
+
+	config := loadHTTPClientConfig(s, prefix, primary)
+	config.Name = name
+
+	// Apply defaults
+	config.setDefaults()
+
+	return config
+}
+
+// loadHTTPClientConfig reads the HTTP client keys under prefix, using the
+// values in fallback for any key that is not set.
+func loadHTTPClientConfig(s *Standard, prefix string, fallback HTTPClientConfig) HTTPClientConfig {
+	return HTTPClientConfig{
+		Timeout:               s.durationOr(prefix+"timeout", fallback.Timeout),
+		DialTimeout:           s.durationOr(prefix+"dial_timeout", fallback.DialTimeout),
+		TLSHandshakeTimeout:   s.durationOr(prefix+"tls_handshake_timeout", fallback.TLSHandshakeTimeout),
+		ResponseHeaderTimeout: s.durationOr(prefix+"response_header_timeout", fallback.ResponseHeaderTimeout),
+		IdleConnTimeout:       s.durationOr(prefix+"idle_conn_timeout", fallback.IdleConnTimeout),
+		MaxIdleConns:          s.intOr(prefix+"max_idle_conns", fallback.MaxIdleConns),
+		MaxIdleConnsPerHost:   s.intOr(prefix+"max_idle_conns_per_host", fallback.MaxIdleConnsPerHost),
+		MaxConnsPerHost:       s.intOr(prefix+"max_conns_per_host", fallback.MaxConnsPerHost),
+		ProxyURL:              s.stringOr(prefix+"proxy_url", fallback.ProxyURL),
+		CAFile:                s.stringOr(prefix+"ca_file", fallback.CAFile),
+		UserAgent:             s.stringOr(prefix+"user_agent", fallback.UserAgent),
+	}
+}
+
+// bindHTTPClientEnv binds the HTTP client keys under prefix to environment
+// variables starting with envPrefix.
+func bindHTTPClientEnv(s *Standard, prefix, envPrefix string) {
+	_ = s.BindEnv(prefix+"timeout", envPrefix+"TIMEOUT")
+	_ = s.BindEnv(prefix+"dial_timeout", envPrefix+"DIAL_TIMEOUT")
+	_ = s.BindEnv(prefix+"tls_handshake_timeout", envPrefix+"TLS_HANDSHAKE_TIMEOUT")
+	_ = s.BindEnv(prefix+"response_header_timeout", envPrefix+"RESPONSE_HEADER_TIMEOUT")
+	_ = s.BindEnv(prefix+"idle_conn_timeout", envPrefix+"IDLE_CONN_TIMEOUT")
+	_ = s.BindEnv(prefix+"max_idle_conns", envPrefix+"MAX_IDLE_CONNS")
+	_ = s.BindEnv(prefix+"max_idle_conns_per_host", envPrefix+"MAX_IDLE_CONNS_PER_HOST")
+	_ = s.BindEnv(prefix+"max_conns_per_host", envPrefix+"MAX_CONNS_PER_HOST")
+	_ = s.BindEnv(prefix+"proxy_url", envPrefix+"PROXY_URL")
+	_ = s.BindEnv(prefix+"ca_file", envPrefix+"CA_FILE")
+	_ = s.BindEnv(prefix+"user_agent", envPrefix+"USER_AGENT")
+}
+
+// setDefaults sets default values for optional fields
+func (c *HTTPClientConfig) setDefaults() {
+	if c.Timeout == 0 {
+		c.Timeout = 30 * time.Second
+	}
+	if c.DialTimeout == 0 {
+		c.DialTimeout = 5 * time.Second
+	}
+	if c.TLSHandshakeTimeout == 0 {
+		c.TLSHandshakeTimeout = 10 * time.Second
+	}
+	if c.IdleConnTimeout == 0 {
+		c.IdleConnTimeout = 90 * time.Second
+	}
+	if c.MaxIdleConns == 0 {
+		c.MaxIdleConns = 100
+	}
+	if c.MaxIdleConnsPerHost == 0 {
+		c.MaxIdleConnsPerHost = 10
+	}
+}
+
+// httpClientEnvSuffixes are the environment variables of the primary
+// http_client block after the HTTP_CLIENT_ prefix. A named client whose
+// HTTP_CLIENT_<NAME>_ prefix starts one of them would be ambiguous, so such
+// names are reserved.
+var httpClientEnvSuffixes = []string{
+	"TIMEOUT", "DIAL_TIMEOUT", "TLS_HANDSHAKE_TIMEOUT", "RESPONSE_HEADER_TIMEOUT", "IDLE_CONN_TIMEOUT",
+	"MAX_IDLE_CONNS", "MAX_IDLE_CONNS_PER_HOST", "MAX_CONNS_PER_HOST", "PROXY_URL", "CA_FILE", "USER_AGENT",
+}
+
+// Validate validates the HTTP client configuration
+func (c *HTTPClientConfig) Validate() error {
+	if suffix := envPrefixClash(c.Name, httpClientEnvSuffixes); c.Name != "" && suffix != "" {
+		return fmt.Errorf("http_clients.%s is a reserved client name: its HTTP_CLIENT_%s_* variables clash with HTTP_CLIENT_%s",
+			c.Name, envName(c.Name), suffix)
+	}
+	if err := ValidateDuration(c.key("timeout"), c.Timeout); err != nil {
+		return err
+	}
+	if err := ValidateDuration(c.key("dial_timeout"), c.DialTimeout); err != nil {
+		return err
+	}
+	if err := ValidateDuration(c.key("tls_handshake_timeout"), c.TLSHandshakeTimeout); err != nil {
+		return err
+	}
+	if err := ValidateDuration(c.key("response_header_timeout"), c.ResponseHeaderTimeout); err != nil {
+		return err
+	}
+	if err := ValidateDuration(c.key("idle_conn_timeout"), c.IdleConnTimeout); err != nil {
+		return err
+	}
+	if err := ValidatePositive(c.key("max_idle_conns"), c.MaxIdleConns); err != nil {
+		return err
+	}
+	if err := ValidatePositive(c.key("max_idle_conns_per_host"), c.MaxIdleConnsPerHost); err != nil {
+		return err
+	}
+	if c.MaxConnsPerHost < 0 {
+		return fmt.Errorf("%s must not be negative, got %d", c.key("max_conns_per_host"), c.MaxConnsPerHost)
+	}
+	if c.MaxConnsPerHost > 0 && c.MaxIdleConnsPerHost > c.MaxConnsPerHost {
+		return fmt.Errorf("%s (%d) must be less than or equal to max_conns_per_host (%d)",
+			c.key("max_idle_conns_per_host"), c.MaxIdleConnsPerHost, c.MaxConnsPerHost)
+	}
+	if c.ProxyURL != "" {
+		if _, err := c.proxyURL(); err != nil {
+			return err
+		}
+	}
+	if c.CAFile != "" {
+		if _, err := c.rootCAs(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// key returns the fully qualified config key for field, used in validation
+// errors so that named instances can be told apart.
+func (c *HTTPClientConfig) key(field string) string {
+	if c.Name == "" {
+		return "http_client." + field
+	}
+	return "http_clients." + c.Name + "." + field
+}
+
+// proxyURL parses ProxyURL
+func (c *HTTPClientConfig) proxyURL() (*url.URL, error) {
+	return parseProxyURL(c.key("proxy_url"), c.ProxyURL)
+}
+
+// parseProxyURL parses an http, https or SOCKS5 proxy URL
+func parseProxyURL(field, value string) (*url.URL, error) {
+	u, err := url.Parse(value)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("%s must be an absolute URL such as http://proxy:3128, got %q", field, value)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("%s scheme must be http, https, socks5 or socks5h, got %q", field, u.Scheme)
+	}
+	return u, nil
+}
+
+// rootCAs returns the system roots with the certificates from CAFile added
+func (c *HTTPClientConfig) rootCAs() (*x509.CertPool, error) {
+	pem, err := os.ReadFile(c.CAFile)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", c.key("ca_file"), err)
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("%s contains no PEM certificates: %s", c.key("ca_file"), c.CAFile)
+	}
+	return pool, nil
+}
+
+// NewTransport returns an *http.Transport tuned with the configured timeouts,
+// pool limits, proxy and root CAs.
+func (c *HTTPClientConfig) NewTransport() (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{
+		Timeout:   c.DialTimeout,
+		KeepAlive: 30 * time.Second,
+	}).DialContext
+	transport.TLSHandshakeTimeout = c.TLSHandshakeTimeout
+	transport.ResponseHeaderTimeout = c.ResponseHeaderTimeout
+	transport.IdleConnTimeout = c.IdleConnTimeout
+	transport.MaxIdleConns = c.MaxIdleConns
+	transport.MaxIdleConnsPerHost = c.MaxIdleConnsPerHost
+	transport.MaxConnsPerHost = c.MaxConnsPerHost
+
+	if c.ProxyURL != "" {
+		proxy, err := c.proxyURL()
+		if err != nil {
+			return nil, err
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	if c.CAFile != "" {
+		pool, err := c.rootCAs()
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig = &tls.Config{
+			RootCAs:    pool,
+			MinVersion: tls.VersionTLS12,
+		}
+	}
+
+	return transport, nil
+}
+
+// NewClient returns an *http.Client using NewTransport, with Timeout applied
+// and UserAgent set on requests that do not carry one.
+func (c *HTTPClientConfig) NewClient() (*http.Client, error) {
+	transport, err := c.NewTransport()
+	if err != nil {
+		return nil, err
+	}
+	return c.newClient(transport), nil
+}
+
+// NewClientWithTransport returns an *http.Client like NewClient whose
+// transport is wrapped by wrap, for adding retries, tracing or metrics
+func (c *HTTPClientConfig) NewClientWithTransport(wrap func(http.RoundTripper) http.RoundTripper) (*http.Client, error) {
+	transport, err := c.NewTransport()
+	if err != nil {
+		return nil, err
+	}
+	return c.newClient(wrap(transport)), nil
+}
+
+// NewClientWithRetry returns an *http.Client like NewClient whose transport
+// retries failed requests according to resilience (see ResilienceConfig.RoundTripper).
+func (c *HTTPClientConfig) NewClientWithRetry(resilience ResilienceConfig) (*http.Client, error) {
+	return c.NewClientWithTransport(resilience.RoundTripper)
+}
+
+// newClient wraps transport with the user agent and applies Timeout
+func (c *HTTPClientConfig) newClient(transport http.RoundTripper) *http.Client {
+	if c.UserAgent != "" {
+		transport = &userAgentTransport{base: transport, userAgent: c.UserAgent}
+	}
+	return &http.Client{
+		Transport: transport,
+		Timeout:   c.Timeout,
+	}
+}
+
+// userAgentTransport sets a default User-Agent header
+type userAgentTransport struct {
+	base      http.RoundTripper
+	userAgent string
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("User-Agent") == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("User-Agent", t.userAgent)
+	}
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,328 @@
+package config_test
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	config "github.com/JohnPlummer/jp-go-config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPClientConfigFromViper(t *testing.T) {
+	t.Run("uses defaults when no config provided", func(t *testing.T) {
+		std, err := config.NewStandard()
+		require.NoError(t, err)
+
+		cfg := config.HTTPClientConfigFromViper(std)
+
+		assert.Equal(t, 30*time.Second, cfg.Timeout)
+		assert.Equal(t, 5*time.Second, cfg.DialTimeout)
+		assert.Equal(t, 10*time.Second, cfg.TLSHandshakeTimeout)
+		assert.Equal(t, 90*time.Second, cfg.IdleConnTimeout)
+		assert.Equal(t, 100, cfg.MaxIdleConns)
+		assert.Equal(t, 10, cfg.MaxIdleConnsPerHost)
+		assert.Zero(t, cfg.MaxConnsPerHost)
+		require.NoError(t, cfg.Validate())
+	})
+
+	t.Run("loads from environment variables", func(t *testing.T) {
+		os.Setenv("HTTP_CLIENT_TIMEOUT", "5s")
+		os.Setenv("HTTP_CLIENT_MAX_IDLE_CONNS_PER_HOST", "32")
+		os.Setenv("HTTP_CLIENT_PROXY_URL", "http://proxy.internal:3128")
+		os.Setenv("HTTP_CLIENT_USER_AGENT", "billing/1.0")
+		defer func() {
+			os.Unsetenv("HTTP_CLIENT_TIMEOUT")
+			os.Unsetenv("HTTP_CLIENT_MAX_IDLE_CONNS_PER_HOST")
+			os.Unsetenv("HTTP_CLIENT_PROXY_URL")
+			os.Unsetenv("HTTP_CLIENT_USER_AGENT")
+		}()
+
+		std, err := config.NewStandard()
+		require.NoError(t, err)
+
+		cfg := config.HTTPClientConfigFromViper(std)
+
+		assert.Equal(t, 5*time.Second, cfg.Timeout)
+		assert.Equal(t, 32, cfg.MaxIdleConnsPerHost)
+		assert.Equal(t, "http://proxy.internal:3128", cfg.ProxyURL)
+		assert.Equal(t, "billing/1.0", cfg.UserAgent)
+	})
+}
+
+func TestHTTPClientConfigFromViperNamed(t *testing.T) {
+	os.Setenv("HTTP_CLIENT_USER_AGENT", "billing/1.0")
+	os.Setenv("HTTP_CLIENT_TIMEOUT", "5s")
+	os.Setenv("HTTP_CLIENT_PAYMENTS_API_TIMEOUT", "2s")
+	defer func() {
+		os.Unsetenv("HTTP_CLIENT_USER_AGENT")
+		os.Unsetenv("HTTP_CLIENT_TIMEOUT")
+		os.Unsetenv("HTTP_CLIENT_PAYMENTS_API_TIMEOUT")
+	}()
+
+	std, err := config.NewStandard()
+	require.NoError(t, err)
+	std.Set("http_clients.payments-api.max_conns_per_host", 20)
+
+	cfg := config.HTTPClientConfigFromViperNamed(std, "payments-api")
+
+	assert.Equal(t, "payments-api", cfg.Name)
+	assert.Equal(t, 2*time.Second, cfg.Timeout)
+	assert.Equal(t, 20, cfg.MaxConnsPerHost)
+	assert.Equal(t, "billing/1.0", cfg.UserAgent, "falls back to the primary block")
+	assert.Equal(t, 90*time.Second, cfg.IdleConnTimeout, "falls back to the defaults")
+
+	cfg.ProxyURL = "ftp://proxy"
+	err = cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "http_clients.payments-api.proxy_url")
+
+	t.Run("rejects reserved client names", func(t *testing.T) {
+		for _, name := range []string{"dial", "max_idle_conns", "proxy"} {
+			cfg := config.HTTPClientConfigFromViperNamed(std, name)
+
+			err := cfg.Validate()
+			require.Error(t, err, name)
+			assert.Contains(t, err.Error(), "http_clients."+name+" is a reserved client name")
+		}
+	})
+}
+
+func validHTTPClientConfig() config.HTTPClientConfig {
+	return config.HTTPClientConfig{
+		Timeout:             30 * time.Second,
+		DialTimeout:         5 * time.Second,
+		TLSHandshakeTimeout: 10 * time.Second,
+		IdleConnTimeout:     90 * time.Second,
+		MaxIdleConns:        100,
+		MaxIdleConnsPerHost: 10,
+	}
+}
+
+func TestHTTPClientConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(cfg *config.HTTPClientConfig)
+		wantErr string
+	}{
+		{name: "valid config passes", modify: func(*config.HTTPClientConfig) {}},
+		{name: "negative timeout fails", modify: func(cfg *config.HTTPClientConfig) { cfg.Timeout = -time.Second }, wantErr: "http_client.timeout"},
+		{name: "negative response header timeout fails", modify: func(cfg *config.HTTPClientConfig) { cfg.ResponseHeaderTimeout = -time.Second }, wantErr: "http_client.response_header_timeout"},
+		{name: "zero idle conns per host fails", modify: func(cfg *config.HTTPClientConfig) { cfg.MaxIdleConnsPerHost = 0 }, wantErr: "http_client.max_idle_conns_per_host"},
+		{name: "idle conns above max conns fails", modify: func(cfg *config.HTTPClientConfig) { cfg.MaxConnsPerHost = 5 }, wantErr: "max_conns_per_host"},
+		{name: "relative proxy url fails", modify: func(cfg *config.HTTPClientConfig) { cfg.ProxyURL = "proxy:3128" }, wantErr: "http_client.proxy_url"},
+		{name: "socks proxy passes", modify: func(cfg *config.HTTPClientConfig) { cfg.ProxyURL = "socks5://127.0.0.1:1080" }},
+		{name: "missing ca file fails", modify: func(cfg *config.HTTPClientConfig) { cfg.CAFile = "/nonexistent/ca.pem" }, wantErr: "http_client.ca_file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validHTTPClientConfig()
+			tt.modify(&cfg)
+
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+
+	t.Run("ca file without certificates fails", func(t *testing.T) {
+		path := t.TempDir() + "/ca.pem"
+		require.NoError(t, os.WriteFile(path, []byte("not a certificate"), 0o600))
+
+		cfg := validHTTPClientConfig()
+		cfg.CAFile = path
+
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no PEM certificates")
+	})
+}
+
+func TestHTTPClientConfig_NewClient(t *testing.T) {
+	t.Run("applies transport settings", func(t *testing.T) {
+		cfg := validHTTPClientConfig()
+		cfg.MaxIdleConnsPerHost = 32
+		cfg.MaxConnsPerHost = 64
+		cfg.ResponseHeaderTimeout = 3 * time.Second
+
+		transport, err := cfg.NewTransport()
+		require.NoError(t, err)
+
+		assert.Equal(t, 32, transport.MaxIdleConnsPerHost)
+		assert.Equal(t, 64, transport.MaxConnsPerHost)
+		assert.Equal(t, 3*time.Second, transport.ResponseHeaderTimeout)
+		assert.Equal(t, 10*time.Second, transport.TLSHandshakeTimeout)
+	})
+
+	t.Run("routes through the proxy", func(t *testing.T) {
+		cfg := validHTTPClientConfig()
+		cfg.ProxyURL = "http://proxy.internal:3128"
+
+		transport, err := cfg.NewTransport()
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodGet, "http://example.com", nil)
+		proxy, err := transport.Proxy(req)
+		require.NoError(t, err)
+		assert.Equal(t, "proxy.internal:3128", proxy.Host)
+	})
+
+	t.Run("sets timeout and user agent", func(t *testing.T) {
+		var userAgent string
+		srv := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+			userAgent = r.UserAgent()
+		}))
+		defer srv.Close()
+
+		cfg := validHTTPClientConfig()
+		cfg.UserAgent = "billing/1.0"
+		client, err := cfg.NewClient()
+		require.NoError(t, err)
+		assert.Equal(t, 30*time.Second, client.Timeout)
+
+		resp, err := client.Get(srv.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, "billing/1.0", userAgent)
+	})
+
+	t.Run("wraps the transport", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+		defer srv.Close()
+
+		var wrapped, called bool
+		cfg := validHTTPClientConfig()
+		client, err := cfg.NewClientWithTransport(func(base http.RoundTripper) http.RoundTripper {
+			_, wrapped = base.(*http.Transport)
+			return roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				called = true
+				return base.RoundTrip(req)
+			})
+		})
+		require.NoError(t, err)
+
+		resp, err := client.Get(srv.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.True(t, wrapped)
+		assert.True(t, called)
+	})
+
+	t.Run("trusts the configured CA", func(t *testing.T) {
+		dir := t.TempDir()
+		ca := generateCert(t, dir, "ca", 1, nil)
+		server := generateCert(t, dir, "server", 2, ca)
+		serverCert, err := tls.LoadX509KeyPair(server.certFile, server.keyFile)
+		require.NoError(t, err)
+
+		srv := httptest.NewUnstartedServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+		srv.TLS = &tls.Config{Certificates: []tls.Certificate{serverCert}}
+		srv.StartTLS()
+		defer srv.Close()
+
+		plainCfg := validHTTPClientConfig()
+		plain, err := plainCfg.NewClient()
+		require.NoError(t, err)
+		_, err = plain.Get(srv.URL)
+		require.Error(t, err, "untrusted without the CA file")
+
+		cfg := validHTTPClientConfig()
+		cfg.CAFile = ca.certFile
+		client, err := cfg.NewClient()
+		require.NoError(t, err)
+
+		resp, err := client.Get(srv.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+}
+
+func TestHTTPClientConfig_NewClientWithRetry(t *testing.T) {
+	resilience := config.ResilienceConfig{
+		MaxRetries:   3,
+		InitialDelay: time.Millisecond,
+		MaxDelay:     10 * time.Millisecond,
+		Multiplier:   2,
+	}
+
+	newServer := func(failures int32) (*httptest.Server, *atomic.Int32) {
+		var calls atomic.Int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			if calls.Add(1) <= failures {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		return srv, &calls
+	}
+
+	cfg := validHTTPClientConfig()
+	client, err := cfg.NewClientWithRetry(resilience)
+	require.NoError(t, err)
+
+	t.Run("retries idempotent requests", func(t *testing.T) {
+		srv, calls := newServer(2)
+		defer srv.Close()
+
+		resp, err := client.Get(srv.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, int32(3), calls.Load())
+	})
+
+	t.Run("gives up after max retries", func(t *testing.T) {
+		srv, calls := newServer(10)
+		defer srv.Close()
+
+		resp, err := client.Get(srv.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+		assert.Equal(t, int32(4), calls.Load())
+	})
+
+	t.Run("does not retry plain POST", func(t *testing.T) {
+		srv, calls := newServer(1)
+		defer srv.Close()
+
+		resp, err := client.Post(srv.URL, "text/plain", strings.NewReader("charge"))
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("retries POST with an idempotency key", func(t *testing.T) {
+		srv, calls := newServer(1)
+		defer srv.Close()
+
+		req, err := http.NewRequest(http.MethodPost, srv.URL, strings.NewReader("charge"))
+		require.NoError(t, err)
+		req.Header.Set("Idempotency-Key", "abc123")
+
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, int32(2), calls.Load())
+	})
+}
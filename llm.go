@@ -0,0 +1,286 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Supported LLM providers
+const (
+	LLMProviderOpenAI           = "openai"
+	LLMProviderOpenAICompatible = "openai_compatible"
+	LLMProviderAnthropic        = "anthropic"
+	LLMProviderAzureOpenAI      = "azure_openai"
+	LLMProviderOllama           = "ollama"
+)
+
+// LLMConfig holds language model configuration for a selectable provider.
+//
+// Provider chooses which of the provider sub-structs applies. The openai and
+// openai_compatible providers read the existing openai.* section, so an
+// OpenAIConfig setup keeps working unchanged with provider openai.
+type LLMConfig struct {
+	// Provider is openai (default), openai_compatible, anthropic, azure_openai or ollama
+	Provider string `mapstructure:"provider"`
+
+	// Generation settings shared by all providers
+	Model       string        `mapstructure:"model"`
+	Temperature float64       `mapstructure:"temperature"`
+	MaxTokens   int           `mapstructure:"max_tokens"`
+	Timeout     time.Duration `mapstructure:"timeout"`
+
+	// Provider-specific settings
+	OpenAI    OpenAIConfig      `mapstructure:"openai"`
+	Anthropic AnthropicConfig   `mapstructure:"anthropic"`
+	Azure     AzureOpenAIConfig `mapstructure:"azure_openai"`
+	Ollama    OllamaConfig      `mapstructure:"ollama"`
+
+	// temperatureSet records that Temperature was configured explicitly, so
+	// that an explicit 0 is not replaced by the default
+	temperatureSet bool
+}
+
+// AnthropicConfig holds Anthropic API settings
+type AnthropicConfig struct {
+	APIKey  string `mapstructure:"api_key"`
+	BaseURL string `mapstructure:"base_url"`
+	// Version is sent as the anthropic-version header
+	Version string `mapstructure:"version"`
+}
+
+// AzureOpenAIConfig holds Azure OpenAI settings. Requests go to
+// <endpoint>/openai/deployments/<deployment>?api-version=<api_version>.
+type AzureOpenAIConfig struct {
+	APIKey     string `mapstructure:"api_key"`
+	Endpoint   string `mapstructure:"endpoint"`
+	Deployment string `mapstructure:"deployment"`
+	APIVersion string `mapstructure:"api_version"`
+}
+
+// OllamaConfig holds settings for a local Ollama server
+type OllamaConfig struct {
+	BaseURL string `mapstructure:"base_url"`
+}
+
+// LLMConfigFromViper creates an LLMConfig from a Standard config loader.
+//
+// Environment variable mappings:
+//   - LLM_PROVIDER -> llm.provider (default: openai)
+//   - LLM_MODEL -> llm.model (openai default: openai.model; required for other providers except azure_openai)
+//   - LLM_TEMPERATURE -> llm.temperature (default: 0.7)
+//   - LLM_MAX_TOKENS -> llm.max_tokens (default: 2000)
+//   - LLM_TIMEOUT -> llm.timeout (default: 30s)
+//   - OPENAI_* -> openai.* (see OpenAIConfigFromViper)
+//   - ANTHROPIC_API_KEY -> anthropic.api_key
+//   - ANTHROPIC_BASE_URL -> anthropic.base_url (default: https://api.anthropic.com)
+//   - ANTHROPIC_VERSION -> anthropic.version (default: 2023-06-01)
+//   - AZURE_OPENAI_API_KEY -> azure_openai.api_key
+//   - AZURE_OPENAI_ENDPOINT -> azure_openai.endpoint
+//   - AZURE_OPENAI_DEPLOYMENT -> azure_openai.deployment
+//   - AZURE_OPENAI_API_VERSION or OPENAI_API_VERSION -> azure_openai.api_version
+//   - OLLAMA_BASE_URL or OLLAMA_HOST -> ollama.base_url (default: http://localhost:11434)
+//
+// With provider openai, the generation settings fall back to the openai.*
+// values, so OPENAI_MODEL and friends keep working.
+func LLMConfigFromViper(s *Standard) LLMConfig {
+	// Bind environment variables
+	_ = s.BindEnv("llm.provider", "LLM_PROVIDER")
+	_ = s.BindEnv("llm.model", "LLM_MODEL")
+	_ = s.BindEnv("llm.temperature", "LLM_TEMPERATURE")
+	_ = s.BindEnv("llm.max_tokens", "LLM_MAX_TOKENS")
+	_ = s.BindEnv("llm.timeout", "LLM_TIMEOUT")
+	_ = s.BindEnv("anthropic.api_key", "ANTHROPIC_API_KEY")
+	_ = s.BindEnv("anthropic.base_url", "ANTHROPIC_BASE_URL")
+	_ = s.BindEnv("anthropic.version", "ANTHROPIC_VERSION")
+	_ = s.BindEnv("azure_openai.api_key", "AZURE_OPENAI_API_KEY")
+	_ = s.BindEnv("azure_openai.endpoint", "AZURE_OPENAI_ENDPOINT")
+	_ = s.BindEnv("azure_openai.deployment", "AZURE_OPENAI_DEPLOYMENT")
+	_ = s.BindEnv("azure_openai.api_version", "AZURE_OPENAI_API_VERSION", "OPENAI_API_VERSION")
+	_ = s.BindEnv("ollama.base_url", "OLLAMA_BASE_URL", "OLLAMA_HOST")
+
+	openai := OpenAIConfigFromViper(s)
+
+	provider := strings.ToLower(s.GetString("llm.provider"))
+	if provider == "" {
+		provider = LLMProviderOpenAI
+	}
+
+	// Only the OpenAI providers inherit the openai.* generation settings
+	var fallback OpenAIConfig
+	if provider == LLMProviderOpenAI || provider == LLMProviderOpenAICompatible {
+		fallback = openai
+	}
+
+	config := LLMConfig{
+		Provider:    provider,
+		Model:       s.stringOr("llm.model", fallback.Model),
+		Temperature: fallback.Temperature,
+		MaxTokens:   s.intOr("llm.max_tokens", fallback.MaxTokens),
+		Timeout:     s.durationOr("llm.timeout", fallback.Timeout),
+		OpenAI:      openai,
+		Anthropic: AnthropicConfig{
+			APIKey:  s.GetString("anthropic.api_key"),
+			BaseURL: s.GetString("anthropic.base_url"),
+			Version: s.GetString("anthropic.version"),
+		},
+		Azure: AzureOpenAIConfig{
+			APIKey:     s.GetString("azure_openai.api_key"),
+			Endpoint:   s.GetString("azure_openai.endpoint"),
+			Deployment: s.GetString("azure_openai.deployment"),
+			APIVersion: s.GetString("azure_openai.api_version"),
+		},
+		Ollama: OllamaConfig{
+			BaseURL: s.GetString("ollama.base_url"),
+		},
+	}
+	// An explicit zero temperature, here or in the openai block, is kept
+	config.temperatureSet = fallback.temperatureSet
+	if s.IsSet("llm.temperature") {
+		config.Temperature = s.viper.GetFloat64("llm.temperature")
+		config.temperatureSet = true
+	}
+
+	// Apply defaults
+	config.setDefaults()
+
+	return config
+}
+
+// setDefaults sets default values for optional fields
+func (c *LLMConfig) setDefaults() {
+	if c.Provider == "" {
+		c.Provider = LLMProviderOpenAI
+	}
+	if c.Temperature == 0 && !c.temperatureSet {
+		c.Temperature = 0.7
+	}
+	if c.MaxTokens == 0 {
+		c.MaxTokens = 2000
+	}
+	if c.Timeout == 0 {
+		c.Timeout = 30 * time.Second
+	}
+	c.OpenAI.setDefaults()
+	if c.Anthropic.BaseURL == "" {
+		c.Anthropic.BaseURL = "https://api.anthropic.com"
+	}
+	if c.Anthropic.Version == "" {
+		c.Anthropic.Version = "2023-06-01"
+	}
+	if c.Azure.APIVersion == "" {
+		c.Azure.APIVersion = "2024-06-01"
+	}
+	if c.Ollama.BaseURL == "" {
+		c.Ollama.BaseURL = "http://localhost:11434"
+	} else if !strings.Contains(c.Ollama.BaseURL, "://") {
+		// OLLAMA_HOST is commonly set as host:port
+		c.Ollama.BaseURL = "http://" + c.Ollama.BaseURL
+	}
+}
+
+// Validate validates the shared settings and those of the selected provider
+func (c *LLMConfig) Validate() error {
+	maxTemperature := 2.0
+	switch c.Provider {
+	case LLMProviderOpenAI:
+		if err := ValidateRequired("openai.api_key", c.OpenAI.APIKey); err != nil {
+			return err
+		}
+		if err := validateHTTPURL("openai.base_url", c.OpenAI.BaseURL); err != nil {
+			return err
+		}
+	case LLMProviderOpenAICompatible:
+		// Gateways may not need an API key, but must not default to api.openai.com
+		if c.OpenAI.BaseURL == defaultOpenAIBaseURL {
+			return fmt.Errorf("openai.base_url must point at the gateway for provider %s", c.Provider)
+		}
+		if err := validateHTTPURL("openai.base_url", c.OpenAI.BaseURL); err != nil {
+			return err
+		}
+	case LLMProviderAnthropic:
+		maxTemperature = 1.0
+		if err := ValidateRequired("anthropic.api_key", c.Anthropic.APIKey); err != nil {
+			return err
+		}
+		if err := validateHTTPURL("anthropic.base_url", c.Anthropic.BaseURL); err != nil {
+			return err
+		}
+		if err := ValidateRequired("anthropic.version", c.Anthropic.Version); err != nil {
+			return err
+		}
+	case LLMProviderAzureOpenAI:
+		if err := ValidateRequired("azure_openai.api_key", c.Azure.APIKey); err != nil {
+			return err
+		}
+		if err := ValidateRequired("azure_openai.endpoint", c.Azure.Endpoint); err != nil {
+			return err
+		}
+		if err := validateHTTPURL("azure_openai.endpoint", c.Azure.Endpoint); err != nil {
+			return err
+		}
+		if err := ValidateRequired("azure_openai.deployment", c.Azure.Deployment); err != nil {
+			return err
+		}
+		if err := ValidateRequired("azure_openai.api_version", c.Azure.APIVersion); err != nil {
+			return err
+		}
+	case LLMProviderOllama:
+		if err := validateHTTPURL("ollama.base_url", c.Ollama.BaseURL); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("llm.provider must be one of: %v", []string{
+			LLMProviderOpenAI, LLMProviderOpenAICompatible, LLMProviderAnthropic,
+			LLMProviderAzureOpenAI, LLMProviderOllama,
+		})
+	}
+
+	// Azure selects the model through the deployment
+	if c.Provider != LLMProviderAzureOpenAI {
+		if err := ValidateRequired("llm.model", c.Model); err != nil {
+			return err
+		}
+	}
+	if err := ValidateRange("llm.temperature", c.Temperature, 0.0, maxTemperature); err != nil {
+		return err
+	}
+	if err := ValidatePositive("llm.max_tokens", c.MaxTokens); err != nil {
+		return err
+	}
+	if err := ValidateDuration("llm.timeout", c.Timeout); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// APIKey returns the API key for the selected provider. It is empty for
+// Ollama and for gateways that do not need one.
+func (c *LLMConfig) APIKey() string {
+	switch c.Provider {
+	case LLMProviderOpenAI, LLMProviderOpenAICompatible:
+		return c.OpenAI.APIKey
+	case LLMProviderAnthropic:
+		return c.Anthropic.APIKey
+	case LLMProviderAzureOpenAI:
+		return c.Azure.APIKey
+	}
+	return ""
+}
+
+// BaseURL returns the API base URL for the selected provider. For Azure
+// OpenAI it includes the deployment path.
+func (c *LLMConfig) BaseURL() string {
+	switch c.Provider {
+	case LLMProviderOpenAI, LLMProviderOpenAICompatible:
+		return c.OpenAI.BaseURL
+	case LLMProviderAnthropic:
+		return c.Anthropic.BaseURL
+	case LLMProviderAzureOpenAI:
+		return strings.TrimSuffix(c.Azure.Endpoint, "/") + "/openai/deployments/" + c.Azure.Deployment
+	case LLMProviderOllama:
+		return c.Ollama.BaseURL
+	}
+	return ""
+}
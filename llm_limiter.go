@@ -0,0 +1,370 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// LLMLimitsConfig holds client-side rate limits and spend budgets for LLM
+// requests. Zero disables a limit.
+type LLMLimitsConfig struct {
+	// Rate limits, matching the provider's per-minute quotas
+	RequestsPerMinute int `mapstructure:"requests_per_minute"`
+	TokensPerMinute   int `mapstructure:"tokens_per_minute"`
+
+	// MaxConcurrentRequests caps requests in flight
+	MaxConcurrentRequests int `mapstructure:"max_concurrent_requests"`
+
+	// Token budgets, reset at midnight UTC and on the first of each month
+	DailyTokenBudget   int64 `mapstructure:"daily_token_budget"`
+	MonthlyTokenBudget int64 `mapstructure:"monthly_token_budget"`
+
+	// Cost budgets in the currency callers report usage in, such as USD
+	DailyCostBudget   float64 `mapstructure:"daily_cost_budget"`
+	MonthlyCostBudget float64 `mapstructure:"monthly_cost_budget"`
+}
+
+// loadLLMLimitsConfig reads the limit keys under prefix, using the values in
+// fallback for any key that is not set.
+func loadLLMLimitsConfig(s *Standard, prefix string, fallback LLMLimitsConfig) LLMLimitsConfig {
+	return LLMLimitsConfig{
+		RequestsPerMinute:     s.intOr(prefix+"requests_per_minute", fallback.RequestsPerMinute),
+		TokensPerMinute:       s.intOr(prefix+"tokens_per_minute", fallback.TokensPerMinute),
+		MaxConcurrentRequests: s.intOr(prefix+"max_concurrent_requests", fallback.MaxConcurrentRequests),
+		DailyTokenBudget:      s.int64Or(prefix+"daily_token_budget", fallback.DailyTokenBudget),
+		MonthlyTokenBudget:    s.int64Or(prefix+"monthly_token_budget", fallback.MonthlyTokenBudget),
+		DailyCostBudget:       s.floatOr(prefix+"daily_cost_budget", fallback.DailyCostBudget),
+		MonthlyCostBudget:     s.floatOr(prefix+"monthly_cost_budget", fallback.MonthlyCostBudget),
+	}
+}
+
+// bindLLMLimitsEnv binds the limit keys under prefix to environment variables
+// starting with envPrefix.
+func bindLLMLimitsEnv(s *Standard, prefix, envPrefix string) {
+	_ = s.BindEnv(prefix+"requests_per_minute", envPrefix+"REQUESTS_PER_MINUTE")
+	_ = s.BindEnv(prefix+"tokens_per_minute", envPrefix+"TOKENS_PER_MINUTE")
+	_ = s.BindEnv(prefix+"max_concurrent_requests", envPrefix+"MAX_CONCURRENT_REQUESTS")
+	_ = s.BindEnv(prefix+"daily_token_budget", envPrefix+"DAILY_TOKEN_BUDGET")
+	_ = s.BindEnv(prefix+"monthly_token_budget", envPrefix+"MONTHLY_TOKEN_BUDGET")
+	_ = s.BindEnv(prefix+"daily_cost_budget", envPrefix+"DAILY_COST_BUDGET")
+	_ = s.BindEnv(prefix+"monthly_cost_budget", envPrefix+"MONTHLY_COST_BUDGET")
+}
+
+// validate checks that the limits are non-negative and consistent with each
+// other and with maxTokens, the largest completion a single request may ask for.
+func (c *LLMLimitsConfig) validate(key func(string) string, maxTokens int) error {
+	for _, limit := range []struct {
+		field string
+		value float64
+	}{
+		{"requests_per_minute", float64(c.RequestsPerMinute)},
+		{"tokens_per_minute", float64(c.TokensPerMinute)},
+		{"max_concurrent_requests", float64(c.MaxConcurrentRequests)},
+		{"daily_token_budget", float64(c.DailyTokenBudget)},
+		{"monthly_token_budget", float64(c.MonthlyTokenBudget)},
+		{"daily_cost_budget", c.DailyCostBudget},
+		{"monthly_cost_budget", c.MonthlyCostBudget},
+	} {
+		if limit.value < 0 {
+			return fmt.Errorf("%s must not be negative, got %v", key(limit.field), limit.value)
+		}
+	}
+
+	// A single request must fit in each token limit
+	if c.TokensPerMinute > 0 && c.TokensPerMinute < maxTokens {
+		return fmt.Errorf("%s (%d) must be at least %s (%d)",
+			key("tokens_per_minute"), c.TokensPerMinute, key("max_tokens"), maxTokens)
+	}
+	if c.DailyTokenBudget > 0 && c.DailyTokenBudget < int64(maxTokens) {
+		return fmt.Errorf("%s (%d) must be at least %s (%d)",
+			key("daily_token_budget"), c.DailyTokenBudget, key("max_tokens"), maxTokens)
+	}
+	if c.MonthlyTokenBudget > 0 && c.MonthlyTokenBudget < int64(maxTokens) {
+		return fmt.Errorf("%s (%d) must be at least %s (%d)",
+			key("monthly_token_budget"), c.MonthlyTokenBudget, key("max_tokens"), maxTokens)
+	}
+
+	if c.DailyTokenBudget > 0 && c.MonthlyTokenBudget > 0 && c.DailyTokenBudget > c.MonthlyTokenBudget {
+		return fmt.Errorf("%s (%d) must not exceed %s (%d)",
+			key("daily_token_budget"), c.DailyTokenBudget, key("monthly_token_budget"), c.MonthlyTokenBudget)
+	}
+	if c.DailyCostBudget > 0 && c.MonthlyCostBudget > 0 && c.DailyCostBudget > c.MonthlyCostBudget {
+		return fmt.Errorf("%s (%v) must not exceed %s (%v)",
+			key("daily_cost_budget"), c.DailyCostBudget, key("monthly_cost_budget"), c.MonthlyCostBudget)
+	}
+	return nil
+}
+
+// ErrLLMBudgetExceeded is returned by LLMLimiter.Acquire when a request would
+// exceed a daily or monthly budget
+var ErrLLMBudgetExceeded = errors.New("llm budget exceeded")
+
+// LLMUsage is the actual consumption of a completed request
+type LLMUsage struct {
+	Tokens int
+	Cost   float64
+}
+
+// LLMLimiter enforces an LLMLimitsConfig: a token bucket per minute for
+// requests and tokens, a semaphore for concurrency and counters for the
+// budgets. It is safe for concurrent use. Budgets are kept in memory, so each
+// process enforces its own.
+type LLMLimiter struct {
+	limits   LLMLimitsConfig
+	requests *tokenBucket
+	tokens   *tokenBucket
+	slots    chan struct{}
+
+	mu     sync.Mutex
+	clock  LimiterClock
+	day    budgetWindow
+	month  budgetWindow
+	period struct{ day, month time.Time }
+}
+
+// budgetWindow tracks spend within the current day or month
+type budgetWindow struct {
+	tokens int64
+	cost   float64
+}
+
+// LLMLimiterOption customises an LLMLimiter
+type LLMLimiterOption func(*LLMLimiter)
+
+// LimiterClock tells the time and waits for an LLMLimiter
+type LimiterClock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+// wallClock is the LimiterClock backed by the time package
+type wallClock struct{}
+
+func (wallClock) Now() time.Time                         { return time.Now() }
+func (wallClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// WithLimiterClock sets the clock used for the rate limit refill, the waits
+// for it and the budget periods, for tests
+func WithLimiterClock(clock LimiterClock) LLMLimiterOption {
+	return func(l *LLMLimiter) {
+		l.clock = clock
+	}
+}
+
+// NewLLMLimiter returns a limiter enforcing limits
+func NewLLMLimiter(limits LLMLimitsConfig, opts ...LLMLimiterOption) *LLMLimiter {
+	l := &LLMLimiter{limits: limits, clock: wallClock{}}
+	for _, opt := range opts {
+		opt(l)
+	}
+	if limits.RequestsPerMinute > 0 {
+		l.requests = newTokenBucket(limits.RequestsPerMinute, l.clock)
+	}
+	if limits.TokensPerMinute > 0 {
+		l.tokens = newTokenBucket(limits.TokensPerMinute, l.clock)
+	}
+	if limits.MaxConcurrentRequests > 0 {
+		l.slots = make(chan struct{}, limits.MaxConcurrentRequests)
+	}
+	return l
+}
+
+// NewLimiter returns an LLMLimiter enforcing the configured limits
+func (c *OpenAIConfig) NewLimiter(opts ...LLMLimiterOption) *LLMLimiter {
+	return NewLLMLimiter(c.Limits, opts...)
+}
+
+// LLMPermit is held for the duration of one request
+type LLMPermit struct {
+	limiter   *LLMLimiter
+	estimated int
+	once      sync.Once
+}
+
+// Acquire waits until a request estimated to use estimatedTokens (prompt plus
+// max_tokens) may be sent, or ctx is done. The estimate is reserved against
+// the token budgets until the permit is released. Acquire fails immediately
+// with an error wrapping ErrLLMBudgetExceeded when the request would exceed a
+// budget.
+func (l *LLMLimiter) Acquire(ctx context.Context, estimatedTokens int) (*LLMPermit, error) {
+	if err := l.reserve(estimatedTokens); err != nil {
+		return nil, err
+	}
+
+	permit := &LLMPermit{limiter: l, estimated: estimatedTokens}
+	if err := l.wait(ctx, estimatedTokens); err != nil {
+		l.settle(estimatedTokens, LLMUsage{})
+		return nil, err
+	}
+	return permit, nil
+}
+
+// wait takes a concurrency slot and waits for the rate limits
+func (l *LLMLimiter) wait(ctx context.Context, estimatedTokens int) error {
+	if l.slots != nil {
+		select {
+		case l.slots <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	var err error
+	if l.requests != nil {
+		err = l.requests.wait(ctx, 1)
+	}
+	if err == nil && l.tokens != nil {
+		if err = l.tokens.wait(ctx, estimatedTokens); err != nil && l.requests != nil {
+			// The request was never sent, so give its request back
+			l.requests.adjust(-1)
+		}
+	}
+	if err != nil && l.slots != nil {
+		<-l.slots
+	}
+	return err
+}
+
+// Release returns the concurrency slot and records the actual usage against
+// the budgets and the tokens per minute limit. It is safe to call more than
+// once; only the first call has an effect.
+func (p *LLMPermit) Release(usage LLMUsage) {
+	p.once.Do(func() {
+		l := p.limiter
+		if l.tokens != nil {
+			l.tokens.adjust(usage.Tokens - p.estimated)
+		}
+		l.settle(p.estimated, usage)
+		if l.slots != nil {
+			<-l.slots
+		}
+	})
+}
+
+// reserve checks the budgets and reserves estimatedTokens against them
+func (l *LLMLimiter) reserve(estimatedTokens int) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.rollover()
+
+	tokens := int64(estimatedTokens)
+	if b := l.limits.DailyTokenBudget; b > 0 && l.day.tokens+tokens > b {
+		return fmt.Errorf("%w: daily token budget of %d would be exceeded (%d used)", ErrLLMBudgetExceeded, b, l.day.tokens)
+	}
+	if b := l.limits.MonthlyTokenBudget; b > 0 && l.month.tokens+tokens > b {
+		return fmt.Errorf("%w: monthly token budget of %d would be exceeded (%d used)", ErrLLMBudgetExceeded, b, l.month.tokens)
+	}
+	if b := l.limits.DailyCostBudget; b > 0 && l.day.cost >= b {
+		return fmt.Errorf("%w: daily cost budget of %v reached (%v spent)", ErrLLMBudgetExceeded, b, l.day.cost)
+	}
+	if b := l.limits.MonthlyCostBudget; b > 0 && l.month.cost >= b {
+		return fmt.Errorf("%w: monthly cost budget of %v reached (%v spent)", ErrLLMBudgetExceeded, b, l.month.cost)
+	}
+
+	l.day.tokens += tokens
+	l.month.tokens += tokens
+	return nil
+}
+
+// settle replaces the reserved estimate with the actual usage
+func (l *LLMLimiter) settle(estimatedTokens int, usage LLMUsage) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.rollover()
+
+	delta := int64(usage.Tokens - estimatedTokens)
+	l.day.tokens = max(l.day.tokens+delta, 0)
+	l.month.tokens = max(l.month.tokens+delta, 0)
+	l.day.cost += usage.Cost
+	l.month.cost += usage.Cost
+}
+
+// rollover resets the budget windows at the start of a new UTC day or month.
+// Callers must hold l.mu.
+func (l *LLMLimiter) rollover() {
+	now := l.clock.Now().UTC()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if !day.Equal(l.period.day) {
+		l.period.day = day
+		l.day = budgetWindow{}
+	}
+	if !month.Equal(l.period.month) {
+		l.period.month = month
+		l.month = budgetWindow{}
+	}
+}
+
+// Usage returns the tokens and cost recorded today and this month, including
+// reservations for requests in flight
+func (l *LLMLimiter) Usage() (day, month LLMUsage) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.rollover()
+	return LLMUsage{Tokens: int(l.day.tokens), Cost: l.day.cost},
+		LLMUsage{Tokens: int(l.month.tokens), Cost: l.month.cost}
+}
+
+// tokenBucket refills at perMinute tokens per minute up to a burst of
+// perMinute. Requests larger than the burst wait for a full bucket and leave
+// it in debt, so they are delayed rather than rejected.
+type tokenBucket struct {
+	mu       sync.Mutex
+	capacity float64
+	rate     float64 // tokens per second
+	tokens   float64
+	last     time.Time
+	clock    LimiterClock
+}
+
+// newTokenBucket returns a full bucket
+func newTokenBucket(perMinute int, clock LimiterClock) *tokenBucket {
+	return &tokenBucket{
+		capacity: float64(perMinute),
+		rate:     float64(perMinute) / 60,
+		tokens:   float64(perMinute),
+		last:     clock.Now(),
+		clock:    clock,
+	}
+}
+
+// refill adds the tokens accrued since the last call. Callers must hold b.mu.
+func (b *tokenBucket) refill() {
+	now := b.clock.Now()
+	b.tokens = min(b.tokens+now.Sub(b.last).Seconds()*b.rate, b.capacity)
+	b.last = now
+}
+
+// wait takes n tokens, blocking until they are available or ctx is done
+func (b *tokenBucket) wait(ctx context.Context, n int) error {
+	for {
+		b.mu.Lock()
+		b.refill()
+		need := min(float64(n), b.capacity)
+		if b.tokens >= need {
+			b.tokens -= float64(n)
+			b.mu.Unlock()
+			return nil
+		}
+		delay := time.Duration((need - b.tokens) / b.rate * float64(time.Second))
+		b.mu.Unlock()
+
+		select {
+		case <-b.clock.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// adjust corrects the bucket after a request used delta more tokens than
+// estimated, or refunds them when delta is negative
+func (b *tokenBucket) adjust(delta int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refill()
+	b.tokens = min(b.tokens-float64(delta), b.capacity)
+}
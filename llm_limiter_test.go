@@ -0,0 +1,265 @@
+package config_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	config "github.com/JohnPlummer/jp-go-config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAIConfig_Limits(t *testing.T) {
+	t.Run("loads limits from environment variables", func(t *testing.T) {
+		os.Setenv("OPENAI_REQUESTS_PER_MINUTE", "500")
+		os.Setenv("OPENAI_TOKENS_PER_MINUTE", "30000")
+		os.Setenv("OPENAI_MAX_CONCURRENT_REQUESTS", "8")
+		os.Setenv("OPENAI_DAILY_TOKEN_BUDGET", "1000000")
+		os.Setenv("OPENAI_MONTHLY_COST_BUDGET", "250.5")
+		defer func() {
+			os.Unsetenv("OPENAI_REQUESTS_PER_MINUTE")
+			os.Unsetenv("OPENAI_TOKENS_PER_MINUTE")
+			os.Unsetenv("OPENAI_MAX_CONCURRENT_REQUESTS")
+			os.Unsetenv("OPENAI_DAILY_TOKEN_BUDGET")
+			os.Unsetenv("OPENAI_MONTHLY_COST_BUDGET")
+		}()
+
+		std, err := config.NewStandard()
+		require.NoError(t, err)
+
+		cfg := config.OpenAIConfigFromViper(std)
+
+		assert.Equal(t, 500, cfg.Limits.RequestsPerMinute)
+		assert.Equal(t, 30000, cfg.Limits.TokensPerMinute)
+		assert.Equal(t, 8, cfg.Limits.MaxConcurrentRequests)
+		assert.Equal(t, int64(1000000), cfg.Limits.DailyTokenBudget)
+		assert.InDelta(t, 250.5, cfg.Limits.MonthlyCostBudget, 0.001)
+	})
+
+	tests := []struct {
+		name    string
+		limits  config.LLMLimitsConfig
+		wantErr string
+	}{
+		{name: "unlimited passes", limits: config.LLMLimitsConfig{}},
+		{name: "consistent limits pass", limits: config.LLMLimitsConfig{
+			RequestsPerMinute: 60, TokensPerMinute: 10000, DailyTokenBudget: 50000, MonthlyTokenBudget: 1000000,
+			DailyCostBudget: 10, MonthlyCostBudget: 200,
+		}},
+		{name: "negative limit fails", limits: config.LLMLimitsConfig{MaxConcurrentRequests: -1}, wantErr: "openai.max_concurrent_requests must not be negative"},
+		{name: "tokens per minute below max tokens fails", limits: config.LLMLimitsConfig{TokensPerMinute: 1000}, wantErr: "openai.tokens_per_minute (1000) must be at least openai.max_tokens (2000)"},
+		{name: "daily token budget below max tokens fails", limits: config.LLMLimitsConfig{DailyTokenBudget: 500}, wantErr: "openai.daily_token_budget"},
+		{name: "daily above monthly token budget fails", limits: config.LLMLimitsConfig{DailyTokenBudget: 50000, MonthlyTokenBudget: 10000}, wantErr: "must not exceed openai.monthly_token_budget"},
+		{name: "daily above monthly cost budget fails", limits: config.LLMLimitsConfig{DailyCostBudget: 20, MonthlyCostBudget: 10}, wantErr: "must not exceed openai.monthly_cost_budget"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.OpenAIConfig{
+				APIKey:      "sk-test123",
+				Model:       "gpt-4o",
+				Temperature: 0.7,
+				MaxTokens:   2000,
+				Timeout:     30 * time.Second,
+				Limits:      tt.limits,
+			}
+
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestLLMLimiter(t *testing.T) {
+	t.Run("enforces the token budget", func(t *testing.T) {
+		limiter := config.NewLLMLimiter(config.LLMLimitsConfig{DailyTokenBudget: 1000})
+
+		permit, err := limiter.Acquire(context.Background(), 800)
+		require.NoError(t, err)
+
+		_, err = limiter.Acquire(context.Background(), 300)
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, config.ErrLLMBudgetExceeded))
+
+		// Actual usage below the estimate frees the difference
+		permit.Release(config.LLMUsage{Tokens: 500})
+		permit.Release(config.LLMUsage{Tokens: 500})
+
+		permit, err = limiter.Acquire(context.Background(), 300)
+		require.NoError(t, err)
+		permit.Release(config.LLMUsage{Tokens: 300})
+
+		day, month := limiter.Usage()
+		assert.Equal(t, 800, day.Tokens)
+		assert.Equal(t, 800, month.Tokens)
+	})
+
+	t.Run("enforces the cost budget", func(t *testing.T) {
+		limiter := config.NewLLMLimiter(config.LLMLimitsConfig{MonthlyCostBudget: 1})
+
+		permit, err := limiter.Acquire(context.Background(), 100)
+		require.NoError(t, err)
+		permit.Release(config.LLMUsage{Tokens: 100, Cost: 1.25})
+
+		_, err = limiter.Acquire(context.Background(), 100)
+		assert.True(t, errors.Is(err, config.ErrLLMBudgetExceeded))
+	})
+
+	t.Run("limits concurrent requests", func(t *testing.T) {
+		limiter := config.NewLLMLimiter(config.LLMLimitsConfig{MaxConcurrentRequests: 1})
+
+		permit, err := limiter.Acquire(context.Background(), 10)
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		_, err = limiter.Acquire(ctx, 10)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+		permit.Release(config.LLMUsage{Tokens: 10})
+		permit, err = limiter.Acquire(context.Background(), 10)
+		require.NoError(t, err)
+		permit.Release(config.LLMUsage{Tokens: 10})
+
+		day, _ := limiter.Usage()
+		assert.Equal(t, 20, day.Tokens, "cancelled requests are not counted")
+	})
+
+	newLimiter := func(limits config.LLMLimitsConfig, start time.Time) (*config.LLMLimiter, *fakeClock) {
+		clock := &fakeClock{now: start}
+		return config.NewLLMLimiter(limits, config.WithLimiterClock(clock)), clock
+	}
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// A cancelled context makes Acquire fail instead of waiting for a refill
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	t.Run("limits requests per minute", func(t *testing.T) {
+		limiter, clock := newLimiter(config.LLMLimitsConfig{RequestsPerMinute: 2}, start)
+
+		for i := 0; i < 2; i++ {
+			permit, err := limiter.Acquire(context.Background(), 10)
+			require.NoError(t, err)
+			permit.Release(config.LLMUsage{Tokens: 10})
+		}
+
+		_, err := limiter.Acquire(cancelled, 10)
+		assert.ErrorIs(t, err, context.Canceled)
+
+		// One request is refilled every 30s
+		clock.Advance(30 * time.Second)
+		permit, err := limiter.Acquire(cancelled, 10)
+		require.NoError(t, err)
+		permit.Release(config.LLMUsage{Tokens: 10})
+
+		_, err = limiter.Acquire(cancelled, 10)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("limits tokens per minute", func(t *testing.T) {
+		limiter, clock := newLimiter(config.LLMLimitsConfig{TokensPerMinute: 6000}, start)
+
+		permit, err := limiter.Acquire(context.Background(), 6000)
+		require.NoError(t, err)
+
+		// Unused tokens are refunded
+		permit.Release(config.LLMUsage{Tokens: 1000})
+		permit, err = limiter.Acquire(context.Background(), 4000)
+		require.NoError(t, err)
+		permit.Release(config.LLMUsage{Tokens: 4000})
+
+		_, err = limiter.Acquire(cancelled, 4000)
+		assert.ErrorIs(t, err, context.Canceled)
+
+		clock.Advance(40 * time.Second)
+		permit, err = limiter.Acquire(cancelled, 4000)
+		require.NoError(t, err)
+		permit.Release(config.LLMUsage{Tokens: 4000})
+	})
+
+	t.Run("a blocked acquire is released by the clock", func(t *testing.T) {
+		limiter, clock := newLimiter(config.LLMLimitsConfig{RequestsPerMinute: 1}, start)
+
+		permit, err := limiter.Acquire(context.Background(), 10)
+		require.NoError(t, err)
+		permit.Release(config.LLMUsage{Tokens: 10})
+
+		acquired := make(chan error, 1)
+		go func() {
+			permit, err := limiter.Acquire(context.Background(), 10)
+			if err == nil {
+				permit.Release(config.LLMUsage{Tokens: 10})
+			}
+			acquired <- err
+		}()
+
+		require.Eventually(t, func() bool { return clock.Waiters() == 1 }, time.Second, time.Millisecond)
+		select {
+		case err := <-acquired:
+			require.FailNow(t, "acquired before the refill", "%v", err)
+		default:
+		}
+
+		clock.Advance(time.Minute)
+		select {
+		case err := <-acquired:
+			require.NoError(t, err)
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "acquire was not released by the clock")
+		}
+	})
+
+	t.Run("refunds the request when the token wait fails", func(t *testing.T) {
+		limiter, _ := newLimiter(config.LLMLimitsConfig{RequestsPerMinute: 2, TokensPerMinute: 1000}, start)
+
+		first, err := limiter.Acquire(context.Background(), 1000)
+		require.NoError(t, err)
+
+		_, err = limiter.Acquire(cancelled, 500)
+		assert.ErrorIs(t, err, context.Canceled)
+
+		// Refunding the tokens leaves the second request available
+		first.Release(config.LLMUsage{})
+		permit, err := limiter.Acquire(cancelled, 500)
+		require.NoError(t, err)
+		permit.Release(config.LLMUsage{Tokens: 500})
+	})
+
+	t.Run("resets budgets at the start of each day and month", func(t *testing.T) {
+		limiter, clock := newLimiter(config.LLMLimitsConfig{DailyTokenBudget: 1000, MonthlyTokenBudget: 1500},
+			time.Date(2025, 1, 31, 23, 59, 0, 0, time.UTC))
+
+		permit, err := limiter.Acquire(context.Background(), 1000)
+		require.NoError(t, err)
+		permit.Release(config.LLMUsage{Tokens: 1000})
+
+		_, err = limiter.Acquire(context.Background(), 100)
+		assert.ErrorIs(t, err, config.ErrLLMBudgetExceeded)
+
+		clock.Advance(2 * time.Minute)
+		day, month := limiter.Usage()
+		assert.Equal(t, 0, day.Tokens)
+		assert.Equal(t, 0, month.Tokens)
+
+		permit, err = limiter.Acquire(context.Background(), 1000)
+		require.NoError(t, err)
+		permit.Release(config.LLMUsage{Tokens: 1000})
+
+		// A new day within the same month keeps the monthly total
+		clock.Advance(24 * time.Hour)
+		_, err = limiter.Acquire(context.Background(), 600)
+		assert.ErrorIs(t, err, config.ErrLLMBudgetExceeded)
+		day, month = limiter.Usage()
+		assert.Equal(t, 0, day.Tokens)
+		assert.Equal(t, 1000, month.Tokens)
+	})
+}
@@ -0,0 +1,190 @@
+package config_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	config "github.com/JohnPlummer/jp-go-config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLLMConfigFromViper(t *testing.T) {
+	t.Run("defaults to openai using the openai section", func(t *testing.T) {
+		os.Setenv("OPENAI_API_KEY", "sk-test")
+		os.Setenv("OPENAI_MODEL", "gpt-4o")
+		os.Setenv("OPENAI_MAX_TOKENS", "500")
+		defer func() {
+			os.Unsetenv("OPENAI_API_KEY")
+			os.Unsetenv("OPENAI_MODEL")
+			os.Unsetenv("OPENAI_MAX_TOKENS")
+		}()
+
+		std, err := config.NewStandard()
+		require.NoError(t, err)
+
+		cfg := config.LLMConfigFromViper(std)
+
+		assert.Equal(t, config.LLMProviderOpenAI, cfg.Provider)
+		assert.Equal(t, "gpt-4o", cfg.Model)
+		assert.Equal(t, 500, cfg.MaxTokens)
+		assert.InDelta(t, 0.7, cfg.Temperature, 0.001)
+		assert.Equal(t, "sk-test", cfg.APIKey())
+		assert.Equal(t, "https://api.openai.com/v1", cfg.BaseURL())
+		require.NoError(t, cfg.Validate())
+	})
+
+	t.Run("loads anthropic from environment variables", func(t *testing.T) {
+		// Ignore any endpoint override from the surrounding environment
+		t.Setenv("ANTHROPIC_BASE_URL", "")
+		os.Setenv("LLM_PROVIDER", "anthropic")
+		os.Setenv("LLM_MODEL", "claude-sonnet-4-5")
+		os.Setenv("LLM_TEMPERATURE", "0.2")
+		os.Setenv("ANTHROPIC_API_KEY", "sk-ant-test")
+		os.Setenv("OPENAI_MODEL", "gpt-4o")
+		defer func() {
+			os.Unsetenv("LLM_PROVIDER")
+			os.Unsetenv("LLM_MODEL")
+			os.Unsetenv("LLM_TEMPERATURE")
+			os.Unsetenv("ANTHROPIC_API_KEY")
+			os.Unsetenv("OPENAI_MODEL")
+		}()
+
+		std, err := config.NewStandard()
+		require.NoError(t, err)
+
+		cfg := config.LLMConfigFromViper(std)
+
+		assert.Equal(t, config.LLMProviderAnthropic, cfg.Provider)
+		assert.Equal(t, "claude-sonnet-4-5", cfg.Model)
+		assert.InDelta(t, 0.2, cfg.Temperature, 0.001)
+		assert.Equal(t, "sk-ant-test", cfg.APIKey())
+		assert.Equal(t, "https://api.anthropic.com", cfg.BaseURL())
+		assert.Equal(t, "2023-06-01", cfg.Anthropic.Version)
+		require.NoError(t, cfg.Validate())
+	})
+
+	t.Run("keeps an explicit zero temperature", func(t *testing.T) {
+		os.Setenv("LLM_PROVIDER", "anthropic")
+		os.Setenv("LLM_TEMPERATURE", "0")
+		defer func() {
+			os.Unsetenv("LLM_PROVIDER")
+			os.Unsetenv("LLM_TEMPERATURE")
+		}()
+
+		std, err := config.NewStandard()
+		require.NoError(t, err)
+
+		cfg := config.LLMConfigFromViper(std)
+
+		assert.Zero(t, cfg.Temperature)
+	})
+
+	t.Run("loads azure openai from environment variables", func(t *testing.T) {
+		os.Setenv("LLM_PROVIDER", "azure_openai")
+		os.Setenv("AZURE_OPENAI_API_KEY", "azure-key")
+		os.Setenv("AZURE_OPENAI_ENDPOINT", "https://example.openai.azure.com/")
+		os.Setenv("AZURE_OPENAI_DEPLOYMENT", "gpt4o-prod")
+		os.Setenv("OPENAI_API_VERSION", "2024-10-21")
+		defer func() {
+			os.Unsetenv("LLM_PROVIDER")
+			os.Unsetenv("AZURE_OPENAI_API_KEY")
+			os.Unsetenv("AZURE_OPENAI_ENDPOINT")
+			os.Unsetenv("AZURE_OPENAI_DEPLOYMENT")
+			os.Unsetenv("OPENAI_API_VERSION")
+		}()
+
+		std, err := config.NewStandard()
+		require.NoError(t, err)
+
+		cfg := config.LLMConfigFromViper(std)
+
+		assert.Equal(t, "2024-10-21", cfg.Azure.APIVersion)
+		assert.Equal(t, "https://example.openai.azure.com/openai/deployments/gpt4o-prod", cfg.BaseURL())
+		require.NoError(t, cfg.Validate(), "model comes from the deployment")
+	})
+
+	t.Run("accepts OLLAMA_HOST as host and port", func(t *testing.T) {
+		os.Setenv("LLM_PROVIDER", "ollama")
+		os.Setenv("LLM_MODEL", "llama3.1")
+		os.Setenv("OLLAMA_HOST", "gpu-box:11434")
+		defer func() {
+			os.Unsetenv("LLM_PROVIDER")
+			os.Unsetenv("LLM_MODEL")
+			os.Unsetenv("OLLAMA_HOST")
+		}()
+
+		std, err := config.NewStandard()
+		require.NoError(t, err)
+
+		cfg := config.LLMConfigFromViper(std)
+
+		assert.Equal(t, "http://gpu-box:11434", cfg.BaseURL())
+		assert.Empty(t, cfg.APIKey())
+		require.NoError(t, cfg.Validate())
+	})
+}
+
+func TestLLMConfig_Validate(t *testing.T) {
+	valid := func(provider string) config.LLMConfig {
+		return config.LLMConfig{
+			Provider:    provider,
+			Model:       "model",
+			Temperature: 0.5,
+			MaxTokens:   1000,
+			Timeout:     30 * time.Second,
+			OpenAI:      config.OpenAIConfig{APIKey: "sk-test", BaseURL: "https://api.openai.com/v1"},
+			Anthropic: config.AnthropicConfig{
+				APIKey:  "sk-ant-test",
+				BaseURL: "https://api.anthropic.com",
+				Version: "2023-06-01",
+			},
+			Azure: config.AzureOpenAIConfig{
+				APIKey:     "azure-key",
+				Endpoint:   "https://example.openai.azure.com",
+				Deployment: "gpt4o-prod",
+				APIVersion: "2024-06-01",
+			},
+			Ollama: config.OllamaConfig{BaseURL: "http://localhost:11434"},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		provider string
+		modify   func(cfg *config.LLMConfig)
+		wantErr  string
+	}{
+		{name: "openai passes", provider: config.LLMProviderOpenAI, modify: func(*config.LLMConfig) {}},
+		{name: "openai requires api key", provider: config.LLMProviderOpenAI, modify: func(cfg *config.LLMConfig) { cfg.OpenAI.APIKey = "" }, wantErr: "openai.api_key"},
+		{name: "gateway needs no api key", provider: config.LLMProviderOpenAICompatible, modify: func(cfg *config.LLMConfig) {
+			cfg.OpenAI.APIKey = ""
+			cfg.OpenAI.BaseURL = "http://litellm:4000/v1"
+		}},
+		{name: "gateway requires its own base url", provider: config.LLMProviderOpenAICompatible, modify: func(*config.LLMConfig) {}, wantErr: "openai.base_url"},
+		{name: "anthropic requires api key", provider: config.LLMProviderAnthropic, modify: func(cfg *config.LLMConfig) { cfg.Anthropic.APIKey = "" }, wantErr: "anthropic.api_key"},
+		{name: "anthropic limits temperature to 1", provider: config.LLMProviderAnthropic, modify: func(cfg *config.LLMConfig) { cfg.Temperature = 1.5 }, wantErr: "llm.temperature"},
+		{name: "azure requires endpoint", provider: config.LLMProviderAzureOpenAI, modify: func(cfg *config.LLMConfig) { cfg.Azure.Endpoint = "" }, wantErr: "azure_openai.endpoint"},
+		{name: "azure requires deployment", provider: config.LLMProviderAzureOpenAI, modify: func(cfg *config.LLMConfig) { cfg.Azure.Deployment = "" }, wantErr: "azure_openai.deployment"},
+		{name: "azure rejects invalid endpoint", provider: config.LLMProviderAzureOpenAI, modify: func(cfg *config.LLMConfig) { cfg.Azure.Endpoint = "example.openai.azure.com" }, wantErr: "azure_openai.endpoint"},
+		{name: "ollama requires model", provider: config.LLMProviderOllama, modify: func(cfg *config.LLMConfig) { cfg.Model = "" }, wantErr: "llm.model"},
+		{name: "unknown provider fails", provider: "bard", modify: func(*config.LLMConfig) {}, wantErr: "llm.provider"},
+		{name: "negative max tokens fails", provider: config.LLMProviderOllama, modify: func(cfg *config.LLMConfig) { cfg.MaxTokens = -1 }, wantErr: "llm.max_tokens"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid(tt.provider)
+			tt.modify(&cfg)
+
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
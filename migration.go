@@ -0,0 +1,154 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MigrationConfig holds database migration configuration.
+//
+// Migrations can run with separate, elevated credentials: Database is loaded as
+// the named database instance "migration", so it inherits the primary database
+// settings and only needs the differing fields (typically user and password).
+type MigrationConfig struct {
+	Dir         string        `mapstructure:"dir"`
+	OnStart     bool          `mapstructure:"on_start"`
+	LockTimeout time.Duration `mapstructure:"lock_timeout"`
+	Table       string        `mapstructure:"table"`
+
+	// Database holds the connection settings used to run migrations
+	Database DatabaseConfig `mapstructure:"-"`
+}
+
+// MigrationConfigFromViper creates a MigrationConfig from a Standard config loader.
+//
+// Environment variable mappings:
+//   - MIGRATION_DIR or MIGRATIONS_DIR -> dir (default: migrations)
+//   - MIGRATION_ON_START or MIGRATE_ON_START -> on_start (default: false)
+//   - MIGRATION_LOCK_TIMEOUT -> lock_timeout (default: 15s)
+//   - MIGRATION_TABLE -> table (default: schema_migrations)
+//
+// Migration credentials are read from databases.migration.* and MIGRATION_DB_*
+// (e.g. MIGRATION_DB_USER, MIGRATION_DB_PASSWORD), falling back to the primary
+// database settings. See DatabaseConfigFromViperNamed.
+func MigrationConfigFromViper(s *Standard) MigrationConfig {
+	// Bind environment variables
+	_ = s.BindEnv("migration.dir", "MIGRATION_DIR", "MIGRATIONS_DIR")
+	_ = s.BindEnv("migration.on_start", "MIGRATION_ON_START", "MIGRATE_ON_START")
+	_ = s.BindEnv("migration.lock_timeout", "MIGRATION_LOCK_TIMEOUT")
+	_ = s.BindEnv("migration.table", "MIGRATION_TABLE")
+
+	config := MigrationConfig{
+		Dir:         s.GetString("migration.dir"),
+		OnStart:     s.GetBool("migration.on_start"),
+		LockTimeout: s.viper.GetDuration("migration.lock_timeout"),
+		Table:       s.GetString("migration.table"),
+		Database:    DatabaseConfigFromViperNamed(s, "migration"),
+	}
+
+	// Apply defaults
+	config.setDefaults()
+
+	return config
+}
+
+// setDefaults sets default values for optional fields
+func (c *MigrationConfig) setDefaults() {
+	if c.Dir == "" {
+		c.Dir = "migrations"
+	}
+	if c.LockTimeout == 0 {
+		c.LockTimeout = 15 * time.Second
+	}
+	if c.Table == "" {
+		c.Table = "schema_migrations"
+	}
+}
+
+// Validate validates the migration configuration, including that the
+// migrations directory exists and its files are well-ordered.
+func (c *MigrationConfig) Validate() error {
+	if err := ValidateRequired("migration.dir", c.Dir); err != nil {
+		return err
+	}
+	if err := ValidateDuration("migration.lock_timeout", c.LockTimeout); err != nil {
+		return err
+	}
+	if err := ValidateRequired("migration.table", c.Table); err != nil {
+		return err
+	}
+	for _, part := range strings.Split(c.Table, ".") {
+		if err := validateIdentifier(part); err != nil {
+			return fmt.Errorf("migration.table part %w", err)
+		}
+	}
+
+	if err := validateMigrationDir(c.Dir); err != nil {
+		return fmt.Errorf("migration.dir %w", err)
+	}
+
+	if err := c.Database.Validate(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// migrationFilePattern matches versioned migration files in the
+// golang-migrate (1_init.up.sql) and goose (1_init.sql) styles.
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_([^.]+)(\.(up|down))?\.sql$`)
+
+// validateMigrationDir checks that dir exists and that every SQL file in it is
+// versioned, with no duplicate versions and no down migration lacking an up.
+func validateMigrationDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("%s is not readable: %w", dir, err)
+	}
+
+	ups := map[uint64]string{}
+	downs := map[uint64]string{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || filepath.Ext(name) != ".sql" {
+			continue
+		}
+
+		match := migrationFilePattern.FindStringSubmatch(name)
+		if match == nil {
+			return fmt.Errorf("file %s is not a versioned migration (expected <version>_<name>[.up|.down].sql)", name)
+		}
+		version, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("file %s has an invalid version: %w", name, err)
+		}
+
+		files := ups
+		if match[4] == "down" {
+			files = downs
+		}
+		if existing, ok := files[version]; ok {
+			return fmt.Errorf("files %s and %s share version %d", existing, name, version)
+		}
+		files[version] = name
+	}
+
+	versions := make([]uint64, 0, len(downs))
+	for version := range downs {
+		versions = append(versions, version)
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i] < versions[j] })
+	for _, version := range versions {
+		if _, ok := ups[version]; !ok {
+			return fmt.Errorf("file %s has no matching up migration", downs[version])
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,179 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	config "github.com/JohnPlummer/jp-go-config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeMigrations creates the named empty files in a new temporary directory
+func writeMigrations(t *testing.T, names ...string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, name := range names {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("SELECT 1;"), 0o644))
+	}
+	return dir
+}
+
+func TestMigrationConfigFromViper(t *testing.T) {
+	t.Run("uses defaults when no config provided", func(t *testing.T) {
+		std, err := config.NewStandard()
+		require.NoError(t, err)
+
+		cfg := config.MigrationConfigFromViper(std)
+
+		assert.Equal(t, "migrations", cfg.Dir)
+		assert.False(t, cfg.OnStart)
+		assert.Equal(t, 15*time.Second, cfg.LockTimeout)
+		assert.Equal(t, "schema_migrations", cfg.Table)
+		assert.Equal(t, "migration", cfg.Database.Name)
+	})
+
+	t.Run("loads from environment variables", func(t *testing.T) {
+		os.Setenv("MIGRATION_DIR", "db/migrations")
+		os.Setenv("MIGRATE_ON_START", "true")
+		os.Setenv("MIGRATION_LOCK_TIMEOUT", "1m")
+		os.Setenv("MIGRATION_TABLE", "public.migrations")
+		defer func() {
+			os.Unsetenv("MIGRATION_DIR")
+			os.Unsetenv("MIGRATE_ON_START")
+			os.Unsetenv("MIGRATION_LOCK_TIMEOUT")
+			os.Unsetenv("MIGRATION_TABLE")
+		}()
+
+		std, err := config.NewStandard()
+		require.NoError(t, err)
+
+		cfg := config.MigrationConfigFromViper(std)
+
+		assert.Equal(t, "db/migrations", cfg.Dir)
+		assert.True(t, cfg.OnStart)
+		assert.Equal(t, 1*time.Minute, cfg.LockTimeout)
+		assert.Equal(t, "public.migrations", cfg.Table)
+	})
+
+	t.Run("uses separate migration credentials", func(t *testing.T) {
+		os.Setenv("DB_HOST", "db.internal")
+		os.Setenv("DB_USER", "app")
+		os.Setenv("DB_PASSWORD", "apppass")
+		os.Setenv("MIGRATION_DB_USER", "owner")
+		os.Setenv("MIGRATION_DB_PASSWORD", "ownerpass")
+		defer func() {
+			os.Unsetenv("DB_HOST")
+			os.Unsetenv("DB_USER")
+			os.Unsetenv("DB_PASSWORD")
+			os.Unsetenv("MIGRATION_DB_USER")
+			os.Unsetenv("MIGRATION_DB_PASSWORD")
+		}()
+
+		std, err := config.NewStandard()
+		require.NoError(t, err)
+
+		app := config.DatabaseConfigFromViper(std)
+		cfg := config.MigrationConfigFromViper(std)
+
+		assert.Equal(t, "app", app.User)
+		assert.Equal(t, "db.internal", cfg.Database.Host)
+		assert.Equal(t, "owner", cfg.Database.User)
+		assert.Equal(t, "ownerpass", cfg.Database.Password)
+	})
+}
+
+func TestMigrationConfig_Validate(t *testing.T) {
+	validConfig := func(dir string) config.MigrationConfig {
+		return config.MigrationConfig{
+			Dir:         dir,
+			LockTimeout: 15 * time.Second,
+			Table:       "schema_migrations",
+			Database: config.DatabaseConfig{
+				Name:     "migration",
+				Host:     "localhost",
+				Port:     5432,
+				Database: "app",
+				User:     "owner",
+				Password: "ownerpass",
+				SSLMode:  "disable",
+				MaxConns: 5,
+			},
+		}
+	}
+
+	t.Run("valid config passes", func(t *testing.T) {
+		dir := writeMigrations(t,
+			"0001_init.up.sql", "0001_init.down.sql",
+			"0002_users.up.sql", "0002_users.down.sql",
+			"20240101120000_goose_style.sql", "README.md",
+		)
+
+		cfg := validConfig(dir)
+		require.NoError(t, cfg.Validate())
+	})
+
+	t.Run("missing directory fails", func(t *testing.T) {
+		cfg := validConfig(filepath.Join(t.TempDir(), "missing"))
+
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "migration.dir")
+		assert.Contains(t, err.Error(), "is not readable")
+	})
+
+	t.Run("unversioned file fails", func(t *testing.T) {
+		cfg := validConfig(writeMigrations(t, "0001_init.up.sql", "add_index.sql"))
+
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "add_index.sql is not a versioned migration")
+	})
+
+	t.Run("duplicate version fails", func(t *testing.T) {
+		cfg := validConfig(writeMigrations(t, "0002_users.up.sql", "2_accounts.up.sql"))
+
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "share version 2")
+	})
+
+	t.Run("down migration without up fails", func(t *testing.T) {
+		cfg := validConfig(writeMigrations(t, "0001_init.up.sql", "0002_users.down.sql"))
+
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "0002_users.down.sql has no matching up migration")
+	})
+
+	t.Run("invalid table name fails", func(t *testing.T) {
+		cfg := validConfig(writeMigrations(t))
+		cfg.Table = "schema migrations"
+
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "migration.table")
+	})
+
+	t.Run("search_path placeholder as table fails", func(t *testing.T) {
+		for _, table := range []string{"$user", "$user.schema_migrations"} {
+			cfg := validConfig(writeMigrations(t))
+			cfg.Table = table
+
+			err := cfg.Validate()
+			require.Error(t, err, table)
+			assert.Contains(t, err.Error(), `migration.table part "$user" is not a valid identifier`)
+		}
+	})
+
+	t.Run("invalid migration credentials fail", func(t *testing.T) {
+		cfg := validConfig(writeMigrations(t))
+		cfg.Database.Password = ""
+
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "databases.migration.password is required")
+	})
+}
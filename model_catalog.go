@@ -0,0 +1,203 @@
+package config
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Model parameters recorded in ModelInfo.Parameters
+const (
+	ModelParamTemperature     = "temperature"
+	ModelParamTopP            = "top_p"
+	ModelParamReasoningEffort = "reasoning_effort"
+	ModelParamDimensions      = "dimensions"
+)
+
+// ModelInfo describes a model's limits and supported request parameters
+type ModelInfo struct {
+	Name     string `json:"name"`
+	Provider string `json:"provider,omitempty"`
+
+	// ContextWindow is the maximum input plus output tokens
+	ContextWindow int `json:"context_window"`
+	// MaxOutputTokens is the largest allowed max_tokens value. It is zero for
+	// embedding models.
+	MaxOutputTokens int `json:"max_output_tokens,omitempty"`
+
+	// Dimensions is the native vector size of an embedding model. Models that
+	// support ModelParamDimensions can return shorter vectors.
+	Dimensions int `json:"dimensions,omitempty"`
+
+	// Parameters lists the optional sampling parameters the model accepts,
+	// such as temperature. Reasoning models reject temperature.
+	Parameters []string `json:"parameters"`
+
+	// ShutdownDate is when the provider retires the model (YYYY-MM-DD)
+	ShutdownDate string `json:"shutdown_date,omitempty"`
+	// Replacement is the suggested successor of a deprecated model
+	Replacement string `json:"replacement,omitempty"`
+}
+
+// Supports reports whether the model accepts param
+func (m ModelInfo) Supports(param string) bool {
+	for _, p := range m.Parameters {
+		if p == param {
+			return true
+		}
+	}
+	return false
+}
+
+// Embedding reports whether the model produces embeddings
+func (m ModelInfo) Embedding() bool {
+	return m.Dimensions > 0
+}
+
+// Shutdown returns the parsed ShutdownDate, if any
+func (m ModelInfo) Shutdown() (time.Time, bool) {
+	if m.ShutdownDate == "" {
+		return time.Time{}, false
+	}
+	date, err := time.Parse(time.DateOnly, m.ShutdownDate)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return date, true
+}
+
+// validate checks that the model entry is usable
+func (m ModelInfo) validate() error {
+	if m.Name == "" {
+		return fmt.Errorf("model name is required")
+	}
+	if m.Dimensions < 0 {
+		return fmt.Errorf("model %s: dimensions must not be negative, got %d", m.Name, m.Dimensions)
+	}
+	if m.Embedding() {
+		if m.ContextWindow <= 0 {
+			return fmt.Errorf("model %s: context_window must be positive, got %d", m.Name, m.ContextWindow)
+		}
+		return m.validateShutdownDate()
+	}
+	if m.MaxOutputTokens <= 0 {
+		return fmt.Errorf("model %s: max_output_tokens must be positive, got %d", m.Name, m.MaxOutputTokens)
+	}
+	if m.ContextWindow < m.MaxOutputTokens {
+		return fmt.Errorf("model %s: context_window (%d) must be at least max_output_tokens (%d)",
+			m.Name, m.ContextWindow, m.MaxOutputTokens)
+	}
+	return m.validateShutdownDate()
+}
+
+// validateShutdownDate checks that ShutdownDate, if set, is a valid date
+func (m ModelInfo) validateShutdownDate() error {
+	if m.ShutdownDate != "" {
+		if _, err := time.Parse(time.DateOnly, m.ShutdownDate); err != nil {
+			return fmt.Errorf("model %s: shutdown_date must be YYYY-MM-DD, got %q", m.Name, m.ShutdownDate)
+		}
+	}
+	return nil
+}
+
+//go:embed models.json
+var builtinModels []byte
+
+// ModelCatalog is a set of known models, safe for concurrent use
+type ModelCatalog struct {
+	mu     sync.RWMutex
+	models map[string]ModelInfo
+}
+
+// DefaultModelCatalog holds the built-in models. Register private or
+// fine-tuned models here to make them known to every OpenAIConfig.
+var DefaultModelCatalog = NewModelCatalog()
+
+// NewModelCatalog returns a catalog holding the built-in models
+func NewModelCatalog() *ModelCatalog {
+	var models []ModelInfo
+	if err := json.Unmarshal(builtinModels, &models); err != nil {
+		panic(fmt.Sprintf("config: invalid built-in model catalog: %v", err))
+	}
+
+	c := &ModelCatalog{models: make(map[string]ModelInfo, len(models))}
+	for _, m := range models {
+		c.models[m.Name] = m
+	}
+	return c
+}
+
+// Register adds or replaces a model
+func (c *ModelCatalog) Register(models ...ModelInfo) error {
+	for _, m := range models {
+		if err := m.validate(); err != nil {
+			return err
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, m := range models {
+		c.models[m.Name] = m
+	}
+	return nil
+}
+
+// LoadFile registers the models in a JSON file holding an array of ModelInfo,
+// overriding built-in entries with the same name
+func (c *ModelCatalog) LoadFile(path string) error {
+	data, err := os.ReadFile(path) // #nosec G304 -- path comes from trusted configuration
+	if err != nil {
+		return fmt.Errorf("failed to read model catalog: %w", err)
+	}
+	var models []ModelInfo
+	if err := json.Unmarshal(data, &models); err != nil {
+		return fmt.Errorf("failed to parse model catalog %s: %w", path, err)
+	}
+	return c.Register(models...)
+}
+
+// Clone returns an independent copy of the catalog
+func (c *ModelCatalog) Clone() *ModelCatalog {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	clone := &ModelCatalog{models: make(map[string]ModelInfo, len(c.models))}
+	for name, m := range c.models {
+		clone.models[name] = m
+	}
+	return clone
+}
+
+// snapshotSuffix matches dated model snapshots such as gpt-4o-2024-08-06
+var snapshotSuffix = regexp.MustCompile(`-\d{4}-\d{2}-\d{2}$`)
+
+// Lookup finds a model by name. Dated snapshots (gpt-4o-2024-08-06) and
+// fine-tuned models (ft:gpt-4o-mini:org::id) resolve to their base model when
+// they are not registered themselves.
+func (c *ModelCatalog) Lookup(name string) (ModelInfo, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if m, ok := c.models[name]; ok {
+		return m, true
+	}
+	if rest, ok := strings.CutPrefix(name, "ft:"); ok {
+		base, _, _ := strings.Cut(rest, ":")
+		if m, ok := c.models[base]; ok {
+			return m, true
+		}
+		name = base
+	}
+	if base := snapshotSuffix.ReplaceAllString(name, ""); base != name {
+		if m, ok := c.models[base]; ok {
+			return m, true
+		}
+	}
+	return ModelInfo{}, false
+}
@@ -0,0 +1,104 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	config "github.com/JohnPlummer/jp-go-config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModelCatalog_Lookup(t *testing.T) {
+	catalog := config.NewModelCatalog()
+
+	t.Run("finds built-in models", func(t *testing.T) {
+		info, ok := catalog.Lookup("gpt-4o")
+		require.True(t, ok)
+		assert.Equal(t, 128000, info.ContextWindow)
+		assert.Equal(t, 16384, info.MaxOutputTokens)
+		assert.True(t, info.Supports(config.ModelParamTemperature))
+	})
+
+	t.Run("records reasoning models without temperature", func(t *testing.T) {
+		info, ok := catalog.Lookup("o3-mini")
+		require.True(t, ok)
+		assert.False(t, info.Supports(config.ModelParamTemperature))
+		assert.True(t, info.Supports(config.ModelParamReasoningEffort))
+	})
+
+	t.Run("resolves dated snapshots and fine-tunes to their base model", func(t *testing.T) {
+		for _, name := range []string{"gpt-4o-mini-2024-07-18", "ft:gpt-4o-mini:acme::abc123", "ft:gpt-4o-mini-2024-07-18:acme::abc123"} {
+			info, ok := catalog.Lookup(name)
+			require.True(t, ok, name)
+			assert.Equal(t, "gpt-4o-mini", info.Name, name)
+		}
+	})
+
+	t.Run("reports unknown models", func(t *testing.T) {
+		_, ok := catalog.Lookup("gpt-99")
+		assert.False(t, ok)
+	})
+
+	t.Run("records shutdown dates", func(t *testing.T) {
+		info, ok := catalog.Lookup("gpt-4.5-preview")
+		require.True(t, ok)
+		date, ok := info.Shutdown()
+		require.True(t, ok)
+		assert.Equal(t, time.Date(2025, 7, 14, 0, 0, 0, 0, time.UTC), date)
+	})
+}
+
+func TestModelCatalog_Register(t *testing.T) {
+	t.Run("registers private models", func(t *testing.T) {
+		catalog := config.NewModelCatalog()
+		require.NoError(t, catalog.Register(config.ModelInfo{
+			Name:            "acme-support-v2",
+			ContextWindow:   32000,
+			MaxOutputTokens: 4000,
+			Parameters:      []string{config.ModelParamTemperature},
+		}))
+
+		info, ok := catalog.Lookup("acme-support-v2")
+		require.True(t, ok)
+		assert.Equal(t, 4000, info.MaxOutputTokens)
+	})
+
+	t.Run("rejects invalid entries", func(t *testing.T) {
+		catalog := config.NewModelCatalog()
+		err := catalog.Register(config.ModelInfo{Name: "broken", ContextWindow: 10, MaxOutputTokens: 100})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "context_window")
+	})
+
+	t.Run("clones are independent", func(t *testing.T) {
+		catalog := config.NewModelCatalog()
+		clone := catalog.Clone()
+		require.NoError(t, clone.Register(config.ModelInfo{Name: "private", ContextWindow: 10, MaxOutputTokens: 10}))
+
+		_, ok := catalog.Lookup("private")
+		assert.False(t, ok)
+	})
+}
+
+func TestModelCatalog_LoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "models.json")
+	require.NoError(t, os.WriteFile(path, []byte(`[
+		{"name": "gpt-4o", "context_window": 128000, "max_output_tokens": 8000, "parameters": ["temperature"]},
+		{"name": "acme-ft", "context_window": 16000, "max_output_tokens": 2000, "parameters": ["temperature"]}
+	]`), 0o600))
+
+	catalog := config.NewModelCatalog()
+	require.NoError(t, catalog.LoadFile(path))
+
+	info, ok := catalog.Lookup("gpt-4o")
+	require.True(t, ok)
+	assert.Equal(t, 8000, info.MaxOutputTokens, "overrides the built-in entry")
+
+	_, ok = catalog.Lookup("acme-ft")
+	assert.True(t, ok)
+
+	require.Error(t, catalog.LoadFile(filepath.Join(t.TempDir(), "missing.json")))
+}
@@ -1,16 +1,65 @@
 package config
 
 import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
 	"time"
 )
 
 // OpenAIConfig holds OpenAI API configuration
 type OpenAIConfig struct {
+	// Name identifies a profile loaded with OpenAIProfile. It is empty for
+	// the base configuration.
+	Name string `mapstructure:"-"`
+
 	APIKey      string        `mapstructure:"api_key"`
 	Model       string        `mapstructure:"model"`
 	Temperature float64       `mapstructure:"temperature"`
 	MaxTokens   int           `mapstructure:"max_tokens"`
 	Timeout     time.Duration `mapstructure:"timeout"`
+
+	// BaseURL points the client at api.openai.com or an OpenAI-compatible gateway
+	BaseURL string `mapstructure:"base_url"`
+
+	// Organization and Project are sent as the OpenAI-Organization and
+	// OpenAI-Project headers
+	Organization string `mapstructure:"organization"`
+	Project      string `mapstructure:"project"`
+
+	// ProxyURL routes API requests through a proxy. When empty, the standard
+	// HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables apply.
+	ProxyURL string `mapstructure:"proxy_url"`
+
+	// Headers are extra request headers, such as those a corporate gateway
+	// requires
+	Headers map[string]string `mapstructure:"headers"`
+
+	// Limits are client-side rate limits and spend budgets, enforced by the
+	// LLMLimiter returned from NewLimiter
+	Limits LLMLimitsConfig `mapstructure:",squash"`
+
+	// Embedding and Batch configure the embeddings endpoint and Batch API jobs
+	Embedding EmbeddingConfig `mapstructure:"embedding"`
+	Batch     BatchConfig     `mapstructure:"batch"`
+
+	// ModelCatalogFile is a JSON file of extra models, such as private
+	// fine-tunes, layered over Catalog
+	ModelCatalogFile string `mapstructure:"model_catalog"`
+
+	// Catalog is checked by Validate. When nil, DefaultModelCatalog is used.
+	Catalog *ModelCatalog `mapstructure:"-"`
+
+	// temperatureSet records that Temperature was configured explicitly, so
+	// that an explicit 0 is not replaced by the default
+	temperatureSet bool
+
+	// models caches Catalog with ModelCatalogFile layered over it, and
+	// modelsErr the error from reading the file (see LoadModelCatalog)
+	models    *ModelCatalog
+	modelsErr error
 }
 
 // OpenAIConfigFromViper creates an OpenAIConfig from a Standard config loader.
@@ -21,21 +70,37 @@ type OpenAIConfig struct {
 //   - OPENAI_TEMPERATURE -> temperature (default: 0.7)
 //   - OPENAI_MAX_TOKENS -> max_tokens (default: 2000)
 //   - OPENAI_TIMEOUT -> timeout (default: 30s)
+//   - OPENAI_BASE_URL -> base_url (default: https://api.openai.com/v1)
+//   - OPENAI_ORG_ID -> organization
+//   - OPENAI_PROJECT_ID -> project
+//   - OPENAI_PROXY_URL -> proxy_url
+//   - OPENAI_MODEL_CATALOG -> model_catalog (JSON file of extra models)
+//   - OPENAI_REQUESTS_PER_MINUTE -> requests_per_minute (default: unlimited)
+//   - OPENAI_TOKENS_PER_MINUTE -> tokens_per_minute (default: unlimited)
+//   - OPENAI_MAX_CONCURRENT_REQUESTS -> max_concurrent_requests (default: unlimited)
+//   - OPENAI_DAILY_TOKEN_BUDGET -> daily_token_budget (default: unlimited)
+//   - OPENAI_MONTHLY_TOKEN_BUDGET -> monthly_token_budget (default: unlimited)
+//   - OPENAI_DAILY_COST_BUDGET -> daily_cost_budget (default: unlimited)
+//   - OPENAI_MONTHLY_COST_BUDGET -> monthly_cost_budget (default: unlimited)
+//   - OPENAI_EMBEDDING_MODEL -> embedding.model (default: text-embedding-3-small)
+//   - OPENAI_EMBEDDING_DIMENSIONS -> embedding.dimensions (default: the model's native size)
+//   - OPENAI_EMBEDDING_BATCH_SIZE -> embedding.batch_size (default: 100)
+//   - OPENAI_EMBEDDING_MAX_INPUT_TOKENS -> embedding.max_input_tokens (default: the model's limit, or 8191)
+//   - OPENAI_BATCH_ENDPOINT -> batch.endpoint (default: /v1/chat/completions)
+//   - OPENAI_BATCH_COMPLETION_WINDOW -> batch.completion_window (default: 24h)
+//   - OPENAI_BATCH_POLL_INTERVAL -> batch.poll_interval (default: 1m)
+//   - OPENAI_BATCH_MAX_REQUESTS -> batch.max_requests (default: 50000)
+//
+// Extra request headers can be set in the openai.headers map.
+//
+// Temperature is only defaulted for models that accept it, and max_tokens is
+// capped at the model's output limit when defaulted.
 func OpenAIConfigFromViper(s *Standard) OpenAIConfig {
 	// Bind environment variables
-	_ = s.BindEnv("openai.api_key", "OPENAI_API_KEY")
-	_ = s.BindEnv("openai.model", "OPENAI_MODEL")
-	_ = s.BindEnv("openai.temperature", "OPENAI_TEMPERATURE")
-	_ = s.BindEnv("openai.max_tokens", "OPENAI_MAX_TOKENS")
-	_ = s.BindEnv("openai.timeout", "OPENAI_TIMEOUT")
+	bindOpenAIEnv(s, "openai.", "OPENAI_")
 
-	config := OpenAIConfig{
-		APIKey:      s.GetString("openai.api_key"),
-		Model:       s.GetString("openai.model"),
-		Temperature: s.viper.GetFloat64("openai.temperature"),
-		MaxTokens:   s.GetInt("openai.max_tokens"),
-		Timeout:     s.viper.GetDuration("openai.timeout"),
-	}
+	config := loadOpenAIConfig(s, "openai.", OpenAIConfig{})
+	_ = config.LoadModelCatalog()
 
 	// Apply defaults
 	config.setDefaults()
@@ -43,39 +108,370 @@ func OpenAIConfigFromViper(s *Standard) OpenAIConfig {
 	return config
 }
 
+// OpenAIProfile creates an OpenAIConfig for a named profile, such as a
+// classifier running at temperature 0 next to a summarizer on a larger model.
+//
+// Values are read from openai.profiles.<name>.* and OPENAI_<NAME>_*
+// environment variables (e.g. OPENAI_SUMMARIZER_MODEL for "summarizer"). Any
+// field that is not set for the profile falls back to the base openai block,
+// then to the defaults. Names whose variables would clash with the base
+// block's, such as "embedding" (OPENAI_EMBEDDING_MODEL), are rejected by
+// Validate.
+func OpenAIProfile(s *Standard, name string) OpenAIConfig {
+	bindOpenAIEnv(s, "openai.", "OPENAI_")
+	base := loadOpenAIConfig(s, "openai.", OpenAIConfig{})
+
+	prefix := "openai.profiles." + name + "."
+	bindOpenAIEnv(s, prefix, "OPENAI_"+envName(name)+"_")
+
+	config := loadOpenAIConfig(s, prefix, base)
+	config.Name = name
+	_ = config.LoadModelCatalog()
+
+	// Apply defaults
+	config.setDefaults()
+
+	return config
+}
+
+// OpenAIProfileNames returns the sorted names of the configured profiles:
+// those with an openai.profiles.<name> block plus any listed in
+// OPENAI_PROFILES (comma-separated). Profiles defined only through
+// OPENAI_<NAME>_* variables must be listed in OPENAI_PROFILES to be found.
+func OpenAIProfileNames(s *Standard) []string {
+	_ = s.BindEnv("openai.profile_names", "OPENAI_PROFILES")
+
+	seen := make(map[string]bool)
+	for name := range s.viper.GetStringMap("openai.profiles") {
+		seen[name] = true
+	}
+	for _, name := range s.stringList("openai.profile_names") {
+		seen[name] = true
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// loadOpenAIConfig reads the OpenAI keys under prefix, using the values in
+// fallback for any key that is not set.
+func loadOpenAIConfig(s *Standard, prefix string, fallback OpenAIConfig) OpenAIConfig {
+	return OpenAIConfig{
+		APIKey:      s.stringOr(prefix+"api_key", fallback.APIKey),
+		Model:       s.stringOr(prefix+"model", fallback.Model),
+		Temperature: s.floatOr(prefix+"temperature", fallback.Temperature),
+		MaxTokens:   s.intOr(prefix+"max_tokens", fallback.MaxTokens),
+		Timeout:     s.durationOr(prefix+"timeout", fallback.Timeout),
+		BaseURL:     s.stringOr(prefix+"base_url", fallback.BaseURL),
+
+		temperatureSet: s.IsSet(prefix+"temperature") || fallback.temperatureSet,
+
+		Organization: s.stringOr(prefix+"organization", fallback.Organization),
+		Project:      s.stringOr(prefix+"project", fallback.Project),
+		ProxyURL:     s.stringOr(prefix+"proxy_url", fallback.ProxyURL),
+		Headers:      s.stringMapOr(prefix+"headers", fallback.Headers),
+
+		ModelCatalogFile: s.stringOr(prefix+"model_catalog", fallback.ModelCatalogFile),
+
+		Limits:    loadLLMLimitsConfig(s, prefix, fallback.Limits),
+		Embedding: loadEmbeddingConfig(s, prefix+"embedding.", fallback.Embedding),
+		Batch:     loadBatchConfig(s, prefix+"batch.", fallback.Batch),
+	}
+}
+
+// bindOpenAIEnv binds the OpenAI keys under prefix to environment variables
+// starting with envPrefix.
+func bindOpenAIEnv(s *Standard, prefix, envPrefix string) {
+	_ = s.BindEnv(prefix+"api_key", envPrefix+"API_KEY")
+	_ = s.BindEnv(prefix+"model", envPrefix+"MODEL")
+	_ = s.BindEnv(prefix+"temperature", envPrefix+"TEMPERATURE")
+	_ = s.BindEnv(prefix+"max_tokens", envPrefix+"MAX_TOKENS")
+	_ = s.BindEnv(prefix+"timeout", envPrefix+"TIMEOUT")
+	_ = s.BindEnv(prefix+"base_url", envPrefix+"BASE_URL")
+	_ = s.BindEnv(prefix+"organization", envPrefix+"ORG_ID")
+	_ = s.BindEnv(prefix+"project", envPrefix+"PROJECT_ID")
+	_ = s.BindEnv(prefix+"proxy_url", envPrefix+"PROXY_URL")
+	_ = s.BindEnv(prefix+"model_catalog", envPrefix+"MODEL_CATALOG")
+	bindLLMLimitsEnv(s, prefix, envPrefix)
+	bindEmbeddingEnv(s, prefix+"embedding.", envPrefix+"EMBEDDING_")
+	bindBatchEnv(s, prefix+"batch.", envPrefix+"BATCH_")
+}
+
 // setDefaults sets default values for optional fields
 func (c *OpenAIConfig) setDefaults() {
 	if c.Model == "" {
 		c.Model = "gpt-3.5-turbo"
 	}
-	if c.Temperature == 0 {
+
+	info, known := ModelInfo{}, false
+	catalog, err := c.catalog()
+	if err == nil {
+		info, known = catalog.Lookup(c.Model)
+	}
+	if c.Temperature == 0 && !c.temperatureSet && (!known || info.Supports(ModelParamTemperature)) {
 		c.Temperature = 0.7
 	}
 	if c.MaxTokens == 0 {
 		c.MaxTokens = 2000
+		if known && info.MaxOutputTokens < c.MaxTokens {
+			c.MaxTokens = info.MaxOutputTokens
+		}
 	}
 	if c.Timeout == 0 {
 		c.Timeout = 30 * time.Second
 	}
+	if c.BaseURL == "" {
+		c.BaseURL = defaultOpenAIBaseURL
+	}
+	c.Embedding.setDefaults(catalog)
+	c.Batch.setDefaults()
+}
+
+// defaultOpenAIBaseURL is the OpenAI API endpoint
+const defaultOpenAIBaseURL = "https://api.openai.com/v1"
+
+// openAIEnvSuffixes are the environment variables of the base openai block
+// after the OPENAI_ prefix. A profile whose OPENAI_<NAME>_ prefix starts one of
+// them would be ambiguous, so such names are reserved.
+var openAIEnvSuffixes = []string{
+	"API_KEY", "MODEL", "TEMPERATURE", "MAX_TOKENS", "TIMEOUT", "BASE_URL",
+	"ORG_ID", "PROJECT_ID", "PROXY_URL", "MODEL_CATALOG", "PROFILES",
+	"REQUESTS_PER_MINUTE", "TOKENS_PER_MINUTE", "MAX_CONCURRENT_REQUESTS",
+	"DAILY_TOKEN_BUDGET", "MONTHLY_TOKEN_BUDGET", "DAILY_COST_BUDGET", "MONTHLY_COST_BUDGET",
+	"EMBEDDING_MODEL", "EMBEDDING_DIMENSIONS", "EMBEDDING_BATCH_SIZE", "EMBEDDING_MAX_INPUT_TOKENS",
+	"BATCH_ENDPOINT", "BATCH_COMPLETION_WINDOW", "BATCH_POLL_INTERVAL", "BATCH_MAX_REQUESTS",
+}
+
+// validateProfileName checks that a profile's environment variables do not
+// clash with those of the base block
+func validateProfileName(name string) error {
+	if suffix := envPrefixClash(name, openAIEnvSuffixes); suffix != "" {
+		return fmt.Errorf("openai.profiles.%s is a reserved profile name: its OPENAI_%s_* variables clash with OPENAI_%s",
+			name, envName(name), suffix)
+	}
+	return nil
 }
 
 // Validate validates the OpenAI configuration
 func (c *OpenAIConfig) Validate() error {
-	if err := ValidateRequired("openai.api_key", c.APIKey); err != nil {
+	if c.Name != "" {
+		if err := validateProfileName(c.Name); err != nil {
+			return err
+		}
+	}
+	if err := ValidateRequired(c.key("api_key"), c.APIKey); err != nil {
+		return err
+	}
+	if err := ValidateRequired(c.key("model"), c.Model); err != nil {
 		return err
 	}
-	if err := ValidateRequired("openai.model", c.Model); err != nil {
+	if err := ValidateRange(c.key("temperature"), c.Temperature, 0.0, 2.0); err != nil {
 		return err
 	}
-	if err := ValidateRange("openai.temperature", c.Temperature, 0.0, 2.0); err != nil {
+	if err := ValidatePositive(c.key("max_tokens"), c.MaxTokens); err != nil {
 		return err
 	}
-	if err := ValidatePositive("openai.max_tokens", c.MaxTokens); err != nil {
+	if err := ValidateDuration(c.key("timeout"), c.Timeout); err != nil {
 		return err
 	}
-	if err := ValidateDuration("openai.timeout", c.Timeout); err != nil {
+	if c.BaseURL != "" {
+		if err := validateHTTPURL(c.key("base_url"), c.BaseURL); err != nil {
+			return err
+		}
+	}
+	if c.ProxyURL != "" {
+		if _, err := parseProxyURL(c.key("proxy_url"), c.ProxyURL); err != nil {
+			return err
+		}
+	}
+	if err := c.Limits.validate(c.key, c.MaxTokens); err != nil {
 		return err
 	}
+	for name, value := range c.Headers {
+		if name == "" || strings.ContainsAny(name, " \t\r\n:") {
+			return fmt.Errorf("%s contains an invalid header name %q", c.key("headers"), name)
+		}
+		if strings.ContainsAny(value, "\r\n") {
+			return fmt.Errorf("%s value for %s must not contain line breaks", c.key("headers"), name)
+		}
+	}
+
+	catalog, err := c.catalog()
+	if err != nil {
+		return fmt.Errorf("%s: %w", c.key("model_catalog"), err)
+	}
+	if info, ok := catalog.Lookup(c.Model); ok {
+		if c.Temperature != 0 && !info.Supports(ModelParamTemperature) {
+			return fmt.Errorf("%s is not supported by model %s, leave it unset", c.key("temperature"), c.Model)
+		}
+		if c.MaxTokens > info.MaxOutputTokens {
+			return fmt.Errorf("%s (%d) exceeds the %d output tokens supported by model %s",
+				c.key("max_tokens"), c.MaxTokens, info.MaxOutputTokens, c.Model)
+		}
+	}
+
+	// The embedding and batch sections are checked when they are filled in,
+	// as they are after OpenAIConfigFromViper
+	if c.Embedding != (EmbeddingConfig{}) {
+		embeddingKey := func(field string) string { return c.key("embedding." + field) }
+		if err := c.Embedding.validate(embeddingKey, catalog); err != nil {
+			return err
+		}
+	}
+	if c.Batch != (BatchConfig{}) {
+		batchKey := func(field string) string { return c.key("batch." + field) }
+		if err := c.Batch.validate(batchKey); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Warnings returns non-fatal problems with the configured models that Validate
+// allows: models missing from the catalog, whose limits cannot be checked, and
+// models that are deprecated or shut down.
+func (c *OpenAIConfig) Warnings() []string {
+	catalog, err := c.catalog()
+	if err != nil {
+		return nil
+	}
+
+	info, ok := catalog.Lookup(c.Model)
+	if !ok {
+		return []string{fmt.Sprintf("%s %s is not in the model catalog, so its limits are not checked", c.key("model"), c.Model)}
+	}
+
+	shutdown, ok := info.Shutdown()
+	if !ok {
+		return nil
+	}
+	warning := fmt.Sprintf("%s %s is deprecated and shuts down on %s", c.key("model"), c.Model, info.ShutdownDate)
+	if !time.Now().Before(shutdown) {
+		warning = fmt.Sprintf("%s %s was shut down on %s", c.key("model"), c.Model, info.ShutdownDate)
+	}
+	if info.Replacement != "" {
+		warning += "; use " + info.Replacement + " instead"
+	}
+	return []string{warning}
+}
+
+// key returns the fully qualified config key for field, used in validation
+// errors so that profiles can be told apart.
+func (c *OpenAIConfig) key(field string) string {
+	if c.Name == "" {
+		return "openai." + field
+	}
+	return "openai.profiles." + c.Name + "." + field
+}
+
+// catalog returns the model catalog to check against, with ModelCatalogFile
+// layered over it when set
+func (c *OpenAIConfig) catalog() (*ModelCatalog, error) {
+	if c.models != nil || c.modelsErr != nil {
+		return c.models, c.modelsErr
+	}
+	return c.readCatalog()
+}
+
+// LoadModelCatalog reads ModelCatalogFile once and caches the resulting
+// catalog for setDefaults, Validate and Warnings, which otherwise read the
+// file on every call. The config loaders call it, so it is only needed for
+// configs built in code, after setting Catalog and ModelCatalogFile. A read
+// error is also reported by Validate.
+func (c *OpenAIConfig) LoadModelCatalog() error {
+	c.models, c.modelsErr = c.readCatalog()
+	return c.modelsErr
+}
+
+// readCatalog returns Catalog, or DefaultModelCatalog, with ModelCatalogFile
+// layered over it
+func (c *OpenAIConfig) readCatalog() (*ModelCatalog, error) {
+	catalog := c.Catalog
+	if catalog == nil {
+		catalog = DefaultModelCatalog
+	}
+	if c.ModelCatalogFile == "" {
+		return catalog, nil
+	}
+
+	catalog = catalog.Clone()
+	if err := catalog.LoadFile(c.ModelCatalogFile); err != nil {
+		return nil, err
+	}
+	return catalog, nil
+}
+
+// RequestHeaders returns the headers to send with every API request: the
+// bearer token, OpenAI-Organization and OpenAI-Project when set, and the
+// configured extra Headers, which take precedence.
+func (c *OpenAIConfig) RequestHeaders() http.Header {
+	header := make(http.Header)
+	if c.APIKey != "" {
+		header.Set("Authorization", "Bearer "+c.APIKey)
+	}
+	if c.Organization != "" {
+		header.Set("OpenAI-Organization", c.Organization)
+	}
+	if c.Project != "" {
+		header.Set("OpenAI-Project", c.Project)
+	}
+	for name, value := range c.Headers {
+		header.Set(name, value)
+	}
+	return header
+}
+
+// HTTPClient returns an *http.Client for API requests, with Timeout applied,
+// ProxyURL honoured and RequestHeaders set on requests that do not already
+// carry them. Pass it to an SDK client along with BaseURL.
+func (c *OpenAIConfig) HTTPClient() (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if c.ProxyURL != "" {
+		proxy, err := parseProxyURL(c.key("proxy_url"), c.ProxyURL)
+		if err != nil {
+			return nil, err
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	return &http.Client{
+		Transport: &headerTransport{base: transport, header: c.RequestHeaders()},
+		Timeout:   c.Timeout,
+	}, nil
+}
 
+// headerTransport sets default request headers
+type headerTransport struct {
+	base   http.RoundTripper
+	header http.Header
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	cloned := false
+	for name, values := range t.header {
+		if req.Header.Get(name) != "" {
+			continue
+		}
+		if !cloned {
+			req = req.Clone(req.Context())
+			cloned = true
+		}
+		req.Header[name] = values
+	}
+	return t.base.RoundTrip(req)
+}
+
+// validateHTTPURL validates that value is an absolute http or https URL
+func validateHTTPURL(field, value string) error {
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%s must be an http or https URL, got %q", field, value)
+	}
 	return nil
 }
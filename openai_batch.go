@@ -0,0 +1,85 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// maxBatchRequests is the most requests the Batch API accepts in one input file
+const maxBatchRequests = 50000
+
+// batchCompletionWindows are the completion windows the Batch API accepts
+var batchCompletionWindows = []string{"24h"}
+
+// batchEndpoints are the endpoints the Batch API can run requests against
+var batchEndpoints = []string{"/v1/chat/completions", "/v1/embeddings", "/v1/completions", "/v1/responses"}
+
+// BatchConfig holds settings for jobs submitted through the Batch API
+type BatchConfig struct {
+	// Endpoint is the API endpoint each request in the batch is sent to
+	Endpoint string `mapstructure:"endpoint"`
+
+	// CompletionWindow is the time frame within which the batch is processed
+	CompletionWindow string `mapstructure:"completion_window"`
+
+	// PollInterval is how often to check the status of a submitted batch
+	PollInterval time.Duration `mapstructure:"poll_interval"`
+
+	// MaxRequests is the most requests to put in one batch input file
+	MaxRequests int `mapstructure:"max_requests"`
+}
+
+// loadBatchConfig reads the batch keys under prefix, using the values in
+// fallback for any key that is not set.
+func loadBatchConfig(s *Standard, prefix string, fallback BatchConfig) BatchConfig {
+	return BatchConfig{
+		Endpoint:         s.stringOr(prefix+"endpoint", fallback.Endpoint),
+		CompletionWindow: s.stringOr(prefix+"completion_window", fallback.CompletionWindow),
+		PollInterval:     s.durationOr(prefix+"poll_interval", fallback.PollInterval),
+		MaxRequests:      s.intOr(prefix+"max_requests", fallback.MaxRequests),
+	}
+}
+
+// bindBatchEnv binds the batch keys under prefix to environment variables
+// starting with envPrefix.
+func bindBatchEnv(s *Standard, prefix, envPrefix string) {
+	_ = s.BindEnv(prefix+"endpoint", envPrefix+"ENDPOINT")
+	_ = s.BindEnv(prefix+"completion_window", envPrefix+"COMPLETION_WINDOW")
+	_ = s.BindEnv(prefix+"poll_interval", envPrefix+"POLL_INTERVAL")
+	_ = s.BindEnv(prefix+"max_requests", envPrefix+"MAX_REQUESTS")
+}
+
+// setDefaults sets default values for optional fields
+func (c *BatchConfig) setDefaults() {
+	if c.Endpoint == "" {
+		c.Endpoint = "/v1/chat/completions"
+	}
+	if c.CompletionWindow == "" {
+		c.CompletionWindow = "24h"
+	}
+	if c.PollInterval == 0 {
+		c.PollInterval = time.Minute
+	}
+	if c.MaxRequests == 0 {
+		c.MaxRequests = maxBatchRequests
+	}
+}
+
+// validate validates the batch settings. key qualifies field names for error
+// messages.
+func (c *BatchConfig) validate(key func(string) string) error {
+	if !slices.Contains(batchEndpoints, c.Endpoint) {
+		return fmt.Errorf("%s must be one of: %v", key("endpoint"), batchEndpoints)
+	}
+	if !slices.Contains(batchCompletionWindows, c.CompletionWindow) {
+		return fmt.Errorf("%s must be one of: %v", key("completion_window"), batchCompletionWindows)
+	}
+	if err := ValidateDuration(key("poll_interval"), c.PollInterval); err != nil {
+		return err
+	}
+	if err := ValidateRange(key("max_requests"), c.MaxRequests, 1, maxBatchRequests); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package config_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	config "github.com/JohnPlummer/jp-go-config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchConfig(t *testing.T) {
+	t.Run("uses defaults when no config provided", func(t *testing.T) {
+		std, err := config.NewStandard()
+		require.NoError(t, err)
+
+		cfg := config.OpenAIConfigFromViper(std)
+
+		assert.Equal(t, "/v1/chat/completions", cfg.Batch.Endpoint)
+		assert.Equal(t, "24h", cfg.Batch.CompletionWindow)
+		assert.Equal(t, time.Minute, cfg.Batch.PollInterval)
+		assert.Equal(t, 50000, cfg.Batch.MaxRequests)
+	})
+
+	t.Run("loads from environment variables", func(t *testing.T) {
+		os.Setenv("OPENAI_BATCH_ENDPOINT", "/v1/embeddings")
+		os.Setenv("OPENAI_BATCH_POLL_INTERVAL", "5m")
+		os.Setenv("OPENAI_BATCH_MAX_REQUESTS", "10000")
+		defer func() {
+			os.Unsetenv("OPENAI_BATCH_ENDPOINT")
+			os.Unsetenv("OPENAI_BATCH_POLL_INTERVAL")
+			os.Unsetenv("OPENAI_BATCH_MAX_REQUESTS")
+		}()
+
+		std, err := config.NewStandard()
+		require.NoError(t, err)
+
+		cfg := config.OpenAIConfigFromViper(std)
+
+		assert.Equal(t, "/v1/embeddings", cfg.Batch.Endpoint)
+		assert.Equal(t, 5*time.Minute, cfg.Batch.PollInterval)
+		assert.Equal(t, 10000, cfg.Batch.MaxRequests)
+	})
+
+	tests := []struct {
+		name    string
+		modify  func(batch *config.BatchConfig)
+		wantErr string
+	}{
+		{name: "valid batch passes", modify: func(*config.BatchConfig) {}},
+		{name: "unknown endpoint fails", modify: func(b *config.BatchConfig) { b.Endpoint = "/v1/images" }, wantErr: "openai.batch.endpoint"},
+		{name: "unsupported completion window fails", modify: func(b *config.BatchConfig) { b.CompletionWindow = "1h" }, wantErr: "openai.batch.completion_window"},
+		{name: "negative poll interval fails", modify: func(b *config.BatchConfig) { b.PollInterval = -time.Second }, wantErr: "openai.batch.poll_interval"},
+		{name: "too many requests fail", modify: func(b *config.BatchConfig) { b.MaxRequests = 60000 }, wantErr: "openai.batch.max_requests"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.OpenAIConfig{
+				APIKey:      "sk-test123",
+				Model:       "gpt-4o",
+				Temperature: 0.7,
+				MaxTokens:   2000,
+				Timeout:     30 * time.Second,
+				Batch: config.BatchConfig{
+					Endpoint:         "/v1/chat/completions",
+					CompletionWindow: "24h",
+					PollInterval:     time.Minute,
+					MaxRequests:      1000,
+				},
+			}
+			tt.modify(&cfg.Batch)
+
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
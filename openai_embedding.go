@@ -0,0 +1,107 @@
+package config
+
+import "fmt"
+
+// maxEmbeddingBatchSize is the most inputs the embeddings endpoint accepts in
+// one request
+const maxEmbeddingBatchSize = 2048
+
+// EmbeddingConfig holds settings for the embeddings endpoint
+type EmbeddingConfig struct {
+	Model string `mapstructure:"model"`
+
+	// Dimensions is the vector size to request. It defaults to the model's
+	// native size, and only models supporting ModelParamDimensions accept a
+	// smaller value.
+	Dimensions int `mapstructure:"dimensions"`
+
+	// BatchSize is the number of inputs sent per request
+	BatchSize int `mapstructure:"batch_size"`
+
+	// MaxInputTokens is the longest input to send; longer inputs should be
+	// split or truncated by the caller
+	MaxInputTokens int `mapstructure:"max_input_tokens"`
+}
+
+// loadEmbeddingConfig reads the embedding keys under prefix, using the values
+// in fallback for any key that is not set.
+func loadEmbeddingConfig(s *Standard, prefix string, fallback EmbeddingConfig) EmbeddingConfig {
+	return EmbeddingConfig{
+		Model:          s.stringOr(prefix+"model", fallback.Model),
+		Dimensions:     s.intOr(prefix+"dimensions", fallback.Dimensions),
+		BatchSize:      s.intOr(prefix+"batch_size", fallback.BatchSize),
+		MaxInputTokens: s.intOr(prefix+"max_input_tokens", fallback.MaxInputTokens),
+	}
+}
+
+// bindEmbeddingEnv binds the embedding keys under prefix to environment
+// variables starting with envPrefix.
+func bindEmbeddingEnv(s *Standard, prefix, envPrefix string) {
+	_ = s.BindEnv(prefix+"model", envPrefix+"MODEL")
+	_ = s.BindEnv(prefix+"dimensions", envPrefix+"DIMENSIONS")
+	_ = s.BindEnv(prefix+"batch_size", envPrefix+"BATCH_SIZE")
+	_ = s.BindEnv(prefix+"max_input_tokens", envPrefix+"MAX_INPUT_TOKENS")
+}
+
+// setDefaults sets default values for optional fields, taking the dimensions
+// and input limit from the catalog entry for the model when it is known
+func (c *EmbeddingConfig) setDefaults(catalog *ModelCatalog) {
+	if c.Model == "" {
+		c.Model = "text-embedding-3-small"
+	}
+
+	info, known := ModelInfo{}, false
+	if catalog != nil {
+		info, known = catalog.Lookup(c.Model)
+	}
+	if c.Dimensions == 0 && known {
+		c.Dimensions = info.Dimensions
+	}
+	if c.BatchSize == 0 {
+		c.BatchSize = 100
+	}
+	if c.MaxInputTokens == 0 {
+		c.MaxInputTokens = 8191
+		if known && info.ContextWindow > 0 {
+			c.MaxInputTokens = info.ContextWindow
+		}
+	}
+}
+
+// validate validates the embedding settings against the model catalog. key
+// qualifies field names for error messages.
+func (c *EmbeddingConfig) validate(key func(string) string, catalog *ModelCatalog) error {
+	if err := ValidateRequired(key("model"), c.Model); err != nil {
+		return err
+	}
+	if c.Dimensions < 0 {
+		return fmt.Errorf("%s must not be negative, got %d", key("dimensions"), c.Dimensions)
+	}
+	if err := ValidateRange(key("batch_size"), c.BatchSize, 1, maxEmbeddingBatchSize); err != nil {
+		return err
+	}
+	if err := ValidatePositive(key("max_input_tokens"), c.MaxInputTokens); err != nil {
+		return err
+	}
+
+	info, ok := catalog.Lookup(c.Model)
+	if !ok {
+		return nil
+	}
+	if !info.Embedding() {
+		return fmt.Errorf("%s %s is not an embedding model", key("model"), c.Model)
+	}
+	if c.Dimensions > info.Dimensions {
+		return fmt.Errorf("%s (%d) exceeds the %d dimensions of model %s",
+			key("dimensions"), c.Dimensions, info.Dimensions, c.Model)
+	}
+	if c.Dimensions != 0 && c.Dimensions != info.Dimensions && !info.Supports(ModelParamDimensions) {
+		return fmt.Errorf("%s must be %d for model %s, which does not support shortened embeddings",
+			key("dimensions"), info.Dimensions, c.Model)
+	}
+	if c.MaxInputTokens > info.ContextWindow {
+		return fmt.Errorf("%s (%d) exceeds the %d input tokens supported by model %s",
+			key("max_input_tokens"), c.MaxInputTokens, info.ContextWindow, c.Model)
+	}
+	return nil
+}
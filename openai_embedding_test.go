@@ -0,0 +1,86 @@
+package config_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	config "github.com/JohnPlummer/jp-go-config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmbeddingConfig(t *testing.T) {
+	t.Run("uses model defaults", func(t *testing.T) {
+		std, err := config.NewStandard()
+		require.NoError(t, err)
+
+		cfg := config.OpenAIConfigFromViper(std)
+
+		assert.Equal(t, "text-embedding-3-small", cfg.Embedding.Model)
+		assert.Equal(t, 1536, cfg.Embedding.Dimensions)
+		assert.Equal(t, 100, cfg.Embedding.BatchSize)
+		assert.Equal(t, 8191, cfg.Embedding.MaxInputTokens)
+	})
+
+	t.Run("loads from environment variables", func(t *testing.T) {
+		os.Setenv("OPENAI_API_KEY", "sk-test")
+		os.Setenv("OPENAI_EMBEDDING_MODEL", "text-embedding-3-large")
+		os.Setenv("OPENAI_EMBEDDING_DIMENSIONS", "1024")
+		os.Setenv("OPENAI_EMBEDDING_BATCH_SIZE", "512")
+		os.Setenv("OPENAI_EMBEDDING_MAX_INPUT_TOKENS", "4000")
+		defer func() {
+			os.Unsetenv("OPENAI_API_KEY")
+			os.Unsetenv("OPENAI_EMBEDDING_MODEL")
+			os.Unsetenv("OPENAI_EMBEDDING_DIMENSIONS")
+			os.Unsetenv("OPENAI_EMBEDDING_BATCH_SIZE")
+			os.Unsetenv("OPENAI_EMBEDDING_MAX_INPUT_TOKENS")
+		}()
+
+		std, err := config.NewStandard()
+		require.NoError(t, err)
+
+		cfg := config.OpenAIConfigFromViper(std)
+
+		assert.Equal(t, "text-embedding-3-large", cfg.Embedding.Model)
+		assert.Equal(t, 1024, cfg.Embedding.Dimensions)
+		assert.Equal(t, 512, cfg.Embedding.BatchSize)
+		assert.Equal(t, 4000, cfg.Embedding.MaxInputTokens)
+		require.NoError(t, cfg.Validate())
+	})
+
+	tests := []struct {
+		name      string
+		embedding config.EmbeddingConfig
+		wantErr   string
+	}{
+		{name: "shortened embeddings pass", embedding: config.EmbeddingConfig{Model: "text-embedding-3-large", Dimensions: 256, BatchSize: 100, MaxInputTokens: 8191}},
+		{name: "unknown models pass", embedding: config.EmbeddingConfig{Model: "nomic-embed-text", Dimensions: 768, BatchSize: 100, MaxInputTokens: 2048}},
+		{name: "dimensions above native size fail", embedding: config.EmbeddingConfig{Model: "text-embedding-3-small", Dimensions: 3072, BatchSize: 100, MaxInputTokens: 8191}, wantErr: "openai.embedding.dimensions (3072) exceeds the 1536 dimensions"},
+		{name: "shortening unsupported model fails", embedding: config.EmbeddingConfig{Model: "text-embedding-ada-002", Dimensions: 512, BatchSize: 100, MaxInputTokens: 8191}, wantErr: "openai.embedding.dimensions must be 1536"},
+		{name: "chat model fails", embedding: config.EmbeddingConfig{Model: "gpt-4o", BatchSize: 100, MaxInputTokens: 8191}, wantErr: "is not an embedding model"},
+		{name: "batch size above limit fails", embedding: config.EmbeddingConfig{Model: "text-embedding-3-small", BatchSize: 5000, MaxInputTokens: 8191}, wantErr: "openai.embedding.batch_size"},
+		{name: "input tokens above model limit fail", embedding: config.EmbeddingConfig{Model: "text-embedding-3-small", BatchSize: 100, MaxInputTokens: 10000}, wantErr: "openai.embedding.max_input_tokens (10000) exceeds"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.OpenAIConfig{
+				APIKey:      "sk-test123",
+				Model:       "gpt-4o",
+				Temperature: 0.7,
+				MaxTokens:   2000,
+				Timeout:     30 * time.Second,
+				Embedding:   tt.embedding,
+			}
+
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
@@ -1,7 +1,10 @@
 package config_test
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -23,6 +26,23 @@ func TestOpenAIConfigFromViper(t *testing.T) {
 		assert.Equal(t, 30*time.Second, cfg.Timeout)
 	})
 
+	t.Run("keeps an explicit zero temperature", func(t *testing.T) {
+		os.Setenv("OPENAI_TEMPERATURE", "0")
+		defer os.Unsetenv("OPENAI_TEMPERATURE")
+
+		std, err := config.NewStandard()
+		require.NoError(t, err)
+
+		cfg := config.OpenAIConfigFromViper(std)
+		assert.Zero(t, cfg.Temperature)
+
+		profile := config.OpenAIProfile(std, "summarizer")
+		assert.Zero(t, profile.Temperature, "profiles inherit the explicit zero")
+
+		llm := config.LLMConfigFromViper(std)
+		assert.Zero(t, llm.Temperature)
+	})
+
 	t.Run("loads from environment variables", func(t *testing.T) {
 		os.Setenv("OPENAI_API_KEY", "sk-test123")
 		os.Setenv("OPENAI_MODEL", "gpt-4")
@@ -131,3 +151,315 @@ func TestOpenAIConfig_Validate(t *testing.T) {
 		assert.Contains(t, err.Error(), "openai.timeout must be positive")
 	})
 }
+
+func TestOpenAIConfig_ModelCatalog(t *testing.T) {
+	t.Run("max tokens above model output limit fails", func(t *testing.T) {
+		cfg := config.OpenAIConfig{
+			APIKey:      "sk-test123",
+			Model:       "gpt-4o",
+			Temperature: 0.7,
+			MaxTokens:   500000,
+			Timeout:     30 * time.Second,
+		}
+
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "exceeds the 16384 output tokens supported by model gpt-4o")
+	})
+
+	t.Run("temperature on reasoning model fails", func(t *testing.T) {
+		cfg := config.OpenAIConfig{
+			APIKey:      "sk-test123",
+			Model:       "o3-mini",
+			Temperature: 0.7,
+			MaxTokens:   2000,
+			Timeout:     30 * time.Second,
+		}
+
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "openai.temperature is not supported by model o3-mini")
+	})
+
+	t.Run("defaults respect the model", func(t *testing.T) {
+		os.Setenv("OPENAI_MODEL", "o3-mini")
+		defer os.Unsetenv("OPENAI_MODEL")
+
+		std, err := config.NewStandard()
+		require.NoError(t, err)
+
+		cfg := config.OpenAIConfigFromViper(std)
+		cfg.APIKey = "sk-test123"
+
+		assert.Zero(t, cfg.Temperature)
+		require.NoError(t, cfg.Validate())
+	})
+
+	t.Run("warns on unknown and deprecated models", func(t *testing.T) {
+		cfg := config.OpenAIConfig{APIKey: "sk-test123", Model: "gpt-99", Temperature: 0.7, MaxTokens: 2000}
+		require.NoError(t, cfg.Validate())
+		require.Len(t, cfg.Warnings(), 1)
+		assert.Contains(t, cfg.Warnings()[0], "not in the model catalog")
+
+		cfg.Model = "gpt-4.5-preview"
+		require.NoError(t, cfg.Validate())
+		require.Len(t, cfg.Warnings(), 1)
+		assert.Contains(t, cfg.Warnings()[0], "use gpt-4.1 instead")
+
+		cfg.Model = "gpt-4o"
+		assert.Empty(t, cfg.Warnings())
+	})
+
+	t.Run("private models from a catalog file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "models.json")
+		require.NoError(t, os.WriteFile(path, []byte(
+			`[{"name": "acme-ft", "context_window": 16000, "max_output_tokens": 1000, "parameters": ["temperature"]}]`,
+		), 0o600))
+
+		cfg := config.OpenAIConfig{
+			APIKey:           "sk-test123",
+			Model:            "acme-ft",
+			Temperature:      0.7,
+			MaxTokens:        2000,
+			ModelCatalogFile: path,
+		}
+
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "exceeds the 1000 output tokens")
+
+		cfg.MaxTokens = 1000
+		require.NoError(t, cfg.Validate())
+		assert.Empty(t, cfg.Warnings())
+	})
+
+	t.Run("custom catalog", func(t *testing.T) {
+		catalog := config.NewModelCatalog()
+		require.NoError(t, catalog.Register(config.ModelInfo{Name: "tiny", ContextWindow: 512, MaxOutputTokens: 256}))
+
+		cfg := config.OpenAIConfig{APIKey: "sk-test123", Model: "tiny", MaxTokens: 256, Catalog: catalog}
+		require.NoError(t, cfg.Validate())
+	})
+
+	t.Run("reads the catalog file once when loading", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "models.json")
+		require.NoError(t, os.WriteFile(path, []byte(
+			`[{"name": "acme-ft", "context_window": 16000, "max_output_tokens": 1000, "parameters": ["temperature"]}]`,
+		), 0o600))
+		os.Setenv("OPENAI_API_KEY", "sk-test123")
+		os.Setenv("OPENAI_MODEL", "acme-ft")
+		os.Setenv("OPENAI_MODEL_CATALOG", path)
+		defer func() {
+			os.Unsetenv("OPENAI_API_KEY")
+			os.Unsetenv("OPENAI_MODEL")
+			os.Unsetenv("OPENAI_MODEL_CATALOG")
+		}()
+
+		std, err := config.NewStandard()
+		require.NoError(t, err)
+		cfg := config.OpenAIConfigFromViper(std)
+		assert.Equal(t, 1000, cfg.MaxTokens)
+
+		require.NoError(t, os.Remove(path))
+		require.NoError(t, cfg.Validate())
+		assert.Empty(t, cfg.Warnings())
+	})
+
+	t.Run("reports catalog file errors from Validate", func(t *testing.T) {
+		cfg := config.OpenAIConfig{
+			APIKey:           "sk-test123",
+			Model:            "gpt-4o",
+			MaxTokens:        1000,
+			ModelCatalogFile: filepath.Join(t.TempDir(), "missing.json"),
+		}
+		require.Error(t, cfg.LoadModelCatalog())
+
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "openai.model_catalog")
+	})
+}
+
+func TestOpenAIProfile(t *testing.T) {
+	tmpDir := t.TempDir()
+	configFile := filepath.Join(tmpDir, "config.yaml")
+	require.NoError(t, os.WriteFile(configFile, []byte(`
+openai:
+  api_key: sk-base
+  model: gpt-4o
+  max_tokens: 4000
+  profiles:
+    classifier:
+      model: gpt-4o-mini
+      temperature: 0
+      max_tokens: 10
+    summarizer:
+      max_tokens: 20000
+`), 0o600))
+
+	t.Run("inherits from the base config", func(t *testing.T) {
+		std, err := config.NewStandard(config.WithConfigFile(configFile))
+		require.NoError(t, err)
+
+		cfg := config.OpenAIProfile(std, "classifier")
+
+		assert.Equal(t, "classifier", cfg.Name)
+		assert.Equal(t, "sk-base", cfg.APIKey)
+		assert.Equal(t, "gpt-4o-mini", cfg.Model)
+		assert.Equal(t, 0.0, cfg.Temperature, "explicit zero temperature is kept")
+		assert.Equal(t, 10, cfg.MaxTokens)
+		assert.Equal(t, 30*time.Second, cfg.Timeout)
+		require.NoError(t, cfg.Validate())
+	})
+
+	t.Run("environment variables override the profile", func(t *testing.T) {
+		os.Setenv("OPENAI_SUMMARIZER_MODEL", "gpt-4.1")
+		defer os.Unsetenv("OPENAI_SUMMARIZER_MODEL")
+
+		std, err := config.NewStandard(config.WithConfigFile(configFile))
+		require.NoError(t, err)
+
+		cfg := config.OpenAIProfile(std, "summarizer")
+
+		assert.Equal(t, "gpt-4.1", cfg.Model)
+		assert.InDelta(t, 0.7, cfg.Temperature, 0.001)
+		assert.Equal(t, 20000, cfg.MaxTokens)
+		require.NoError(t, cfg.Validate())
+	})
+
+	t.Run("validates each profile independently", func(t *testing.T) {
+		std, err := config.NewStandard(config.WithConfigFile(configFile))
+		require.NoError(t, err)
+
+		base := config.OpenAIConfigFromViper(std)
+		require.NoError(t, base.Validate())
+
+		cfg := config.OpenAIProfile(std, "summarizer")
+		err = cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "openai.profiles.summarizer.max_tokens")
+	})
+
+	t.Run("rejects reserved profile names", func(t *testing.T) {
+		std, err := config.NewStandard(config.WithConfigFile(configFile))
+		require.NoError(t, err)
+
+		for _, name := range []string{"embedding", "batch", "model", "max_concurrent"} {
+			cfg := config.OpenAIProfile(std, name)
+
+			err := cfg.Validate()
+			require.Error(t, err, name)
+			assert.Contains(t, err.Error(), "openai.profiles."+name+" is a reserved profile name")
+		}
+	})
+
+	t.Run("lists configured profiles", func(t *testing.T) {
+		os.Setenv("OPENAI_PROFILES", "extractor, classifier")
+		defer os.Unsetenv("OPENAI_PROFILES")
+
+		std, err := config.NewStandard(config.WithConfigFile(configFile))
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"classifier", "extractor", "summarizer"}, config.OpenAIProfileNames(std))
+	})
+}
+
+func TestOpenAIConfig_Endpoint(t *testing.T) {
+	t.Run("defaults to the OpenAI API", func(t *testing.T) {
+		std, err := config.NewStandard()
+		require.NoError(t, err)
+
+		cfg := config.OpenAIConfigFromViper(std)
+
+		assert.Equal(t, "https://api.openai.com/v1", cfg.BaseURL)
+		assert.Empty(t, cfg.Organization)
+		assert.Empty(t, cfg.ProxyURL)
+	})
+
+	t.Run("loads endpoint settings from environment variables", func(t *testing.T) {
+		os.Setenv("OPENAI_BASE_URL", "https://gateway.internal/v1")
+		os.Setenv("OPENAI_ORG_ID", "org-123")
+		os.Setenv("OPENAI_PROJECT_ID", "proj_456")
+		os.Setenv("OPENAI_PROXY_URL", "http://proxy:3128")
+		defer func() {
+			os.Unsetenv("OPENAI_BASE_URL")
+			os.Unsetenv("OPENAI_ORG_ID")
+			os.Unsetenv("OPENAI_PROJECT_ID")
+			os.Unsetenv("OPENAI_PROXY_URL")
+		}()
+
+		std, err := config.NewStandard()
+		require.NoError(t, err)
+
+		cfg := config.OpenAIConfigFromViper(std)
+
+		assert.Equal(t, "https://gateway.internal/v1", cfg.BaseURL)
+		assert.Equal(t, "org-123", cfg.Organization)
+		assert.Equal(t, "proj_456", cfg.Project)
+		assert.Equal(t, "http://proxy:3128", cfg.ProxyURL)
+	})
+
+	t.Run("invalid settings fail validation", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			modify  func(cfg *config.OpenAIConfig)
+			wantErr string
+		}{
+			{name: "base url", modify: func(cfg *config.OpenAIConfig) { cfg.BaseURL = "gateway.internal" }, wantErr: "openai.base_url"},
+			{name: "proxy url", modify: func(cfg *config.OpenAIConfig) { cfg.ProxyURL = "ftp://proxy" }, wantErr: "openai.proxy_url"},
+			{name: "header name", modify: func(cfg *config.OpenAIConfig) { cfg.Headers = map[string]string{"X Team": "a"} }, wantErr: "openai.headers"},
+			{name: "header value", modify: func(cfg *config.OpenAIConfig) { cfg.Headers = map[string]string{"X-Team": "a\r\nb"} }, wantErr: "openai.headers"},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				cfg := config.OpenAIConfig{
+					APIKey:      "sk-test123",
+					Model:       "gpt-4o",
+					Temperature: 0.7,
+					MaxTokens:   2000,
+					Timeout:     30 * time.Second,
+				}
+				tt.modify(&cfg)
+
+				err := cfg.Validate()
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			})
+		}
+	})
+
+	t.Run("http client sends the configured headers", func(t *testing.T) {
+		var got http.Header
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = r.Header.Clone()
+		}))
+		defer server.Close()
+
+		cfg := config.OpenAIConfig{
+			APIKey:       "sk-test123",
+			BaseURL:      server.URL,
+			Organization: "org-123",
+			Project:      "proj_456",
+			Headers:      map[string]string{"X-Team": "search"},
+			Timeout:      5 * time.Second,
+		}
+
+		client, err := cfg.HTTPClient()
+		require.NoError(t, err)
+		assert.Equal(t, 5*time.Second, client.Timeout)
+
+		req, err := http.NewRequest(http.MethodGet, cfg.BaseURL+"/models", nil)
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer sk-override")
+
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		assert.Equal(t, "Bearer sk-override", got.Get("Authorization"), "request headers win")
+		assert.Equal(t, "org-123", got.Get("OpenAI-Organization"))
+		assert.Equal(t, "proj_456", got.Get("OpenAI-Project"))
+		assert.Equal(t, "search", got.Get("X-Team"))
+	})
+}
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// PasswordProvider supplies a password along with the time it expires.
+// A zero expiry means the password does not expire.
+//
+// Implementations typically wrap AWS RDS IAM auth, Cloud SQL IAM or Vault
+// dynamic secrets, all of which issue short-lived credentials.
+type PasswordProvider interface {
+	Password(ctx context.Context) (password string, expiresAt time.Time, err error)
+}
+
+// PasswordProviderFunc adapts an ordinary function to a PasswordProvider
+type PasswordProviderFunc func(ctx context.Context) (string, time.Time, error)
+
+// Password calls f(ctx)
+func (f PasswordProviderFunc) Password(ctx context.Context) (string, time.Time, error) {
+	return f(ctx)
+}
+
+// StaticPassword is a PasswordProvider that always returns the same password
+// and never expires.
+type StaticPassword string
+
+// Password returns the static password
+func (p StaticPassword) Password(context.Context) (string, time.Time, error) {
+	return string(p), time.Time{}, nil
+}
+
+// CachedPasswordProvider caches the password from another provider until
+// shortly before it expires, so that each new connection does not trigger a
+// round trip to the credential source.
+type CachedPasswordProvider struct {
+	provider      PasswordProvider
+	refreshBefore time.Duration
+
+	mu        sync.Mutex
+	password  string
+	expiresAt time.Time
+	cached    bool
+}
+
+// NewCachedPasswordProvider creates a CachedPasswordProvider that refreshes the
+// password refreshBefore ahead of its expiry.
+func NewCachedPasswordProvider(provider PasswordProvider, refreshBefore time.Duration) *CachedPasswordProvider {
+	return &CachedPasswordProvider{
+		provider:      provider,
+		refreshBefore: refreshBefore,
+	}
+}
+
+// Password returns the cached password, fetching a new one from the wrapped
+// provider if none is cached or the cached one is about to expire.
+func (p *CachedPasswordProvider) Password(ctx context.Context) (string, time.Time, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.cached && (p.expiresAt.IsZero() || time.Now().Add(p.refreshBefore).Before(p.expiresAt)) {
+		return p.password, p.expiresAt, nil
+	}
+
+	password, expiresAt, err := p.provider.Password(ctx)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	p.password = password
+	p.expiresAt = expiresAt
+	p.cached = true
+
+	return password, expiresAt, nil
+}
+
+// Invalidate discards the cached password, forcing the next call to Password
+// to fetch a new one. Call it after an authentication failure.
+func (p *CachedPasswordProvider) Invalidate() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.password = ""
+	p.expiresAt = time.Time{}
+	p.cached = false
+}
@@ -0,0 +1,108 @@
+package config_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	config "github.com/JohnPlummer/jp-go-config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStaticPassword(t *testing.T) {
+	password, expiresAt, err := config.StaticPassword("secret").Password(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, "secret", password)
+	assert.True(t, expiresAt.IsZero())
+}
+
+func TestCachedPasswordProvider(t *testing.T) {
+	t.Run("caches password until near expiry", func(t *testing.T) {
+		calls := 0
+		provider := config.NewCachedPasswordProvider(config.PasswordProviderFunc(
+			func(context.Context) (string, time.Time, error) {
+				calls++
+				return "token", time.Now().Add(15 * time.Minute), nil
+			},
+		), time.Minute)
+
+		for range 3 {
+			password, _, err := provider.Password(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, "token", password)
+		}
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("refreshes password within refresh window", func(t *testing.T) {
+		calls := 0
+		provider := config.NewCachedPasswordProvider(config.PasswordProviderFunc(
+			func(context.Context) (string, time.Time, error) {
+				calls++
+				return "token", time.Now().Add(30 * time.Second), nil
+			},
+		), time.Minute)
+
+		_, _, err := provider.Password(context.Background())
+		require.NoError(t, err)
+		_, _, err = provider.Password(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("never refreshes non-expiring password", func(t *testing.T) {
+		calls := 0
+		provider := config.NewCachedPasswordProvider(config.PasswordProviderFunc(
+			func(context.Context) (string, time.Time, error) {
+				calls++
+				return "static", time.Time{}, nil
+			},
+		), time.Minute)
+
+		_, _, err := provider.Password(context.Background())
+		require.NoError(t, err)
+		_, _, err = provider.Password(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("invalidate forces refresh", func(t *testing.T) {
+		calls := 0
+		provider := config.NewCachedPasswordProvider(config.PasswordProviderFunc(
+			func(context.Context) (string, time.Time, error) {
+				calls++
+				return "token", time.Now().Add(time.Hour), nil
+			},
+		), time.Minute)
+
+		_, _, err := provider.Password(context.Background())
+		require.NoError(t, err)
+		provider.Invalidate()
+		_, _, err = provider.Password(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("does not cache errors", func(t *testing.T) {
+		calls := 0
+		provider := config.NewCachedPasswordProvider(config.PasswordProviderFunc(
+			func(context.Context) (string, time.Time, error) {
+				calls++
+				if calls == 1 {
+					return "", time.Time{}, errors.New("token service unavailable")
+				}
+				return "token", time.Now().Add(time.Hour), nil
+			},
+		), time.Minute)
+
+		_, _, err := provider.Password(context.Background())
+		require.Error(t, err)
+
+		password, _, err := provider.Password(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "token", password)
+	})
+}
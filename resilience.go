@@ -2,6 +2,9 @@ package config
 
 import (
 	"fmt"
+	"maps"
+	"slices"
+	"sort"
 	"time"
 )
 
@@ -9,51 +12,112 @@ import (
 // This provides standardized resilience settings that can be used across
 // all packages that implement retry and circuit breaker patterns.
 type ResilienceConfig struct {
+	// Name identifies a dependency loaded with ResilienceConfigFor. It is
+	// empty for the global settings.
+	Name string `mapstructure:"-"`
+
+	// Preset names the preset the settings started from, if any. Fields set
+	// explicitly override the preset's values.
+	Preset        string `mapstructure:"preset"`
+	unknownPreset bool
+
+	// sources records where each field's value came from, keyed by config key
+	sources map[string]string
+
 	// Retry settings
 	MaxRetries   int           `mapstructure:"max_retries"`
 	InitialDelay time.Duration `mapstructure:"initial_delay"`
 	MaxDelay     time.Duration `mapstructure:"max_delay"`
 	Multiplier   float64       `mapstructure:"multiplier"`
+	// Jitter randomises the delays: none, full, equal or decorrelated
+	Jitter string `mapstructure:"jitter"`
+
+	// RetryStatusCodes are the HTTP responses RoundTripper retries
+	// (default: 429, 502, 503 and 504)
+	RetryStatusCodes  []int `mapstructure:"retry_status_codes"`
+	invalidStatusCode string
+
+	// Retryable decides which errors Retry retries. When nil, every error is
+	// retried except those wrapped with Permanent and context errors.
+	Retryable func(err error) bool `mapstructure:"-"`
 
 	// Circuit breaker settings
 	MaxRequests      uint32        `mapstructure:"max_requests"`
 	Interval         time.Duration `mapstructure:"interval"`
 	Timeout          time.Duration `mapstructure:"timeout"`
 	FailureThreshold float64       `mapstructure:"failure_threshold"`
+
+	// Bulkhead settings: MaxConcurrent caps calls in flight (0 is unlimited)
+	// and MaxQueueWait bounds the wait for a free slot (0 waits for the context)
+	MaxConcurrent int           `mapstructure:"max_concurrent"`
+	MaxQueueWait  time.Duration `mapstructure:"max_queue_wait"`
+
+	// Budget is the overall deadline for a call including all retries
+	// (0 is unlimited)
+	Budget time.Duration `mapstructure:"budget"`
+
+	// Hedging settings: up to MaxHedges extra attempts are started when a call
+	// is slower than the HedgePercentile latency of recent calls, or than
+	// HedgeDelay until enough calls have been seen (0 hedges disables hedging)
+	MaxHedges       int           `mapstructure:"max_hedges"`
+	HedgeDelay      time.Duration `mapstructure:"hedge_delay"`
+	HedgePercentile float64       `mapstructure:"hedge_percentile"`
 }
 
 // ResilienceConfigFromViper creates a ResilienceConfig from a Standard config loader.
 //
+// RESILIENCE_PRESET selects a preset (interactive, background, critical, none
+// or one added with RegisterResiliencePreset or a resilience.presets.<name>
+// block). The preset is applied before the defaults, and any field set
+// explicitly overrides it.
+//
 // Environment variable mappings:
+//   - RESILIENCE_PRESET -> preset (default: none selected)
 //   - RESILIENCE_MAX_RETRIES -> max_retries (default: 3)
 //   - RESILIENCE_INITIAL_DELAY -> initial_delay (default: 1s)
 //   - RESILIENCE_MAX_DELAY -> max_delay (default: 30s)
 //   - RESILIENCE_MULTIPLIER -> multiplier (default: 2.0)
+//   - RESILIENCE_JITTER -> jitter (default: none)
+//   - RESILIENCE_RETRY_STATUS_CODES -> retry_status_codes (comma-separated; default: 429,502,503,504)
 //   - RESILIENCE_MAX_REQUESTS -> max_requests (default: 10)
 //   - RESILIENCE_INTERVAL -> interval (default: 10s)
 //   - RESILIENCE_TIMEOUT -> timeout (default: 60s)
 //   - RESILIENCE_FAILURE_THRESHOLD -> failure_threshold (default: 0.6)
+//   - RESILIENCE_MAX_CONCURRENT -> max_concurrent (default: unlimited)
+//   - RESILIENCE_MAX_QUEUE_WAIT -> max_queue_wait (default: wait for the context)
+//   - RESILIENCE_BUDGET -> budget (default: unlimited)
+//   - RESILIENCE_MAX_HEDGES -> max_hedges (default: 0, no hedging)
+//   - RESILIENCE_HEDGE_DELAY -> hedge_delay
+//   - RESILIENCE_HEDGE_PERCENTILE -> hedge_percentile (e.g. 0.95; default: fixed hedge_delay)
 func ResilienceConfigFromViper(s *Standard) ResilienceConfig {
 	// Bind environment variables
-	_ = s.BindEnv("resilience.max_retries", "RESILIENCE_MAX_RETRIES")
-	_ = s.BindEnv("resilience.initial_delay", "RESILIENCE_INITIAL_DELAY")
-	_ = s.BindEnv("resilience.max_delay", "RESILIENCE_MAX_DELAY")
-	_ = s.BindEnv("resilience.multiplier", "RESILIENCE_MULTIPLIER")
-	_ = s.BindEnv("resilience.max_requests", "RESILIENCE_MAX_REQUESTS")
-	_ = s.BindEnv("resilience.interval", "RESILIENCE_INTERVAL")
-	_ = s.BindEnv("resilience.timeout", "RESILIENCE_TIMEOUT")
-	_ = s.BindEnv("resilience.failure_threshold", "RESILIENCE_FAILURE_THRESHOLD")
-
-	config := ResilienceConfig{
-		MaxRetries:       s.GetInt("resilience.max_retries"),
-		InitialDelay:     s.viper.GetDuration("resilience.initial_delay"),
-		MaxDelay:         s.viper.GetDuration("resilience.max_delay"),
-		Multiplier:       s.viper.GetFloat64("resilience.multiplier"),
-		MaxRequests:      s.viper.GetUint32("resilience.max_requests"),
-		Interval:         s.viper.GetDuration("resilience.interval"),
-		Timeout:          s.viper.GetDuration("resilience.timeout"),
-		FailureThreshold: s.viper.GetFloat64("resilience.failure_threshold"),
-	}
+	bindResilienceEnv(s, "resilience.", "RESILIENCE_")
+
+	config := resolveResilienceConfig(s, "resilience.", ResilienceConfig{})
+
+	// Apply defaults
+	config.setDefaults()
+
+	return config
+}
+
+// ResilienceConfigFor creates a ResilienceConfig for a named dependency, such
+// as an LLM API that needs more retries and a longer timeout than a cache.
+//
+// Values are read from resilience.services.<name>.* and RESILIENCE_<NAME>_*
+// environment variables (e.g. RESILIENCE_OPENAI_MAX_RETRIES for "openai").
+// Any field that is not set for the dependency falls back to the global
+// resilience block, then to the defaults. A dependency that selects its own
+// preset falls back to that preset instead of the global block.
+func ResilienceConfigFor(s *Standard, name string) ResilienceConfig {
+	bindResilienceEnv(s, "resilience.", "RESILIENCE_")
+	global := resolveResilienceConfig(s, "resilience.", ResilienceConfig{})
+
+	prefix := "resilience.services." + name + "."
+	bindResilienceEnv(s, prefix, "RESILIENCE_"+envName(name)+"_")
+
+	config := resolveResilienceConfig(s, prefix, global)
+	config.Name = name
 
 	// Apply defaults
 	config.setDefaults()
@@ -61,10 +125,156 @@ func ResilienceConfigFromViper(s *Standard) ResilienceConfig {
 	return config
 }
 
+// ResilienceServiceNames returns the sorted names of the dependencies with
+// their own settings: those with a resilience.services.<name> block plus any
+// listed in RESILIENCE_SERVICES (comma-separated). Dependencies configured
+// only through RESILIENCE_<NAME>_* variables must be listed in
+// RESILIENCE_SERVICES to be found.
+func ResilienceServiceNames(s *Standard) []string {
+	_ = s.BindEnv("resilience.service_names", "RESILIENCE_SERVICES")
+
+	seen := make(map[string]bool)
+	for name := range s.viper.GetStringMap("resilience.services") {
+		seen[name] = true
+	}
+	for _, name := range s.stringList("resilience.service_names") {
+		seen[name] = true
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// resolveResilienceConfig reads the resilience keys under prefix on top of
+// the preset selected there, or on top of fallback when none is selected.
+func resolveResilienceConfig(s *Standard, prefix string, fallback ResilienceConfig) ResilienceConfig {
+	base := fallback
+	if name := s.stringOr(prefix+"preset", ""); name != "" {
+		base = s.resiliencePreset(name)
+	}
+	return loadResilienceConfig(s, prefix, base)
+}
+
+// loadResilienceConfig reads the resilience keys under prefix, using the
+// values in fallback for any key that is not set.
+func loadResilienceConfig(s *Standard, prefix string, fallback ResilienceConfig) ResilienceConfig {
+	statusCodes, invalidStatusCode := s.intListOr(prefix+"retry_status_codes", fallback.RetryStatusCodes)
+	if !s.IsSet(prefix + "retry_status_codes") {
+		invalidStatusCode = fallback.invalidStatusCode
+	}
+
+	sources := maps.Clone(fallback.sources)
+	for key := range fallback.fieldsSet() {
+		if s.IsSet(prefix + key) {
+			if sources == nil {
+				sources = make(map[string]string)
+			}
+			sources[key] = SourceExplicit
+		}
+	}
+
+	c := ResilienceConfig{
+		Preset:           fallback.Preset,
+		unknownPreset:    fallback.unknownPreset,
+		MaxRetries:       s.intOr(prefix+"max_retries", fallback.MaxRetries),
+		InitialDelay:     s.durationOr(prefix+"initial_delay", fallback.InitialDelay),
+		MaxDelay:         s.durationOr(prefix+"max_delay", fallback.MaxDelay),
+		Multiplier:       s.floatOr(prefix+"multiplier", fallback.Multiplier),
+		Jitter:           s.stringOr(prefix+"jitter", fallback.Jitter),
+		RetryStatusCodes: statusCodes,
+		MaxRequests:      s.uint32Or(prefix+"max_requests", fallback.MaxRequests),
+		Interval:         s.durationOr(prefix+"interval", fallback.Interval),
+		Timeout:          s.durationOr(prefix+"timeout", fallback.Timeout),
+		FailureThreshold: s.floatOr(prefix+"failure_threshold", fallback.FailureThreshold),
+		MaxConcurrent:    s.intOr(prefix+"max_concurrent", fallback.MaxConcurrent),
+		MaxQueueWait:     s.durationOr(prefix+"max_queue_wait", fallback.MaxQueueWait),
+		Budget:           s.durationOr(prefix+"budget", fallback.Budget),
+		MaxHedges:        s.intOr(prefix+"max_hedges", fallback.MaxHedges),
+		HedgeDelay:       s.durationOr(prefix+"hedge_delay", fallback.HedgeDelay),
+		HedgePercentile:  s.floatOr(prefix+"hedge_percentile", fallback.HedgePercentile),
+	}
+	c.invalidStatusCode = invalidStatusCode
+	c.sources = sources
+	return c
+}
+
+// bindResilienceEnv binds the resilience keys under prefix to environment
+// variables starting with envPrefix.
+func bindResilienceEnv(s *Standard, prefix, envPrefix string) {
+	_ = s.BindEnv(prefix+"preset", envPrefix+"PRESET")
+	_ = s.BindEnv(prefix+"max_retries", envPrefix+"MAX_RETRIES")
+	_ = s.BindEnv(prefix+"initial_delay", envPrefix+"INITIAL_DELAY")
+	_ = s.BindEnv(prefix+"max_delay", envPrefix+"MAX_DELAY")
+	_ = s.BindEnv(prefix+"multiplier", envPrefix+"MULTIPLIER")
+	_ = s.BindEnv(prefix+"jitter", envPrefix+"JITTER")
+	_ = s.BindEnv(prefix+"retry_status_codes", envPrefix+"RETRY_STATUS_CODES")
+	_ = s.BindEnv(prefix+"max_requests", envPrefix+"MAX_REQUESTS")
+	_ = s.BindEnv(prefix+"interval", envPrefix+"INTERVAL")
+	_ = s.BindEnv(prefix+"timeout", envPrefix+"TIMEOUT")
+	_ = s.BindEnv(prefix+"failure_threshold", envPrefix+"FAILURE_THRESHOLD")
+	_ = s.BindEnv(prefix+"max_concurrent", envPrefix+"MAX_CONCURRENT")
+	_ = s.BindEnv(prefix+"max_queue_wait", envPrefix+"MAX_QUEUE_WAIT")
+	_ = s.BindEnv(prefix+"budget", envPrefix+"BUDGET")
+	_ = s.BindEnv(prefix+"max_hedges", envPrefix+"MAX_HEDGES")
+	_ = s.BindEnv(prefix+"hedge_delay", envPrefix+"HEDGE_DELAY")
+	_ = s.BindEnv(prefix+"hedge_percentile", envPrefix+"HEDGE_PERCENTILE")
+}
+
+// Sources of ResilienceConfig field values, reported by Sources
+const (
+	// SourceDefault marks a field left at its default
+	SourceDefault = "default"
+	// SourcePreset marks a field supplied by the selected preset
+	SourcePreset = "from preset"
+	// SourceExplicit marks a field set in the config file or environment
+	SourceExplicit = "explicit"
+)
+
+// Sources returns where each field's value came from, keyed by config key
+// (e.g. "max_retries"): SourceExplicit, SourcePreset or SourceDefault. A
+// dependency loaded with ResilienceConfigFor reports the source of any field
+// it inherits from the global block.
+func (c *ResilienceConfig) Sources() map[string]string {
+	sources := make(map[string]string)
+	for key := range c.fieldsSet() {
+		sources[key] = SourceDefault
+		if source, ok := c.sources[key]; ok {
+			sources[key] = source
+		}
+	}
+	return sources
+}
+
+// fieldsSet reports, by config key, which tunable fields hold a non-zero value
+func (c *ResilienceConfig) fieldsSet() map[string]bool {
+	return map[string]bool{
+		"max_retries":        c.MaxRetries != 0,
+		"initial_delay":      c.InitialDelay != 0,
+		"max_delay":          c.MaxDelay != 0,
+		"multiplier":         c.Multiplier != 0,
+		"jitter":             c.Jitter != "",
+		"retry_status_codes": len(c.RetryStatusCodes) > 0,
+		"max_requests":       c.MaxRequests != 0,
+		"interval":           c.Interval != 0,
+		"timeout":            c.Timeout != 0,
+		"failure_threshold":  c.FailureThreshold != 0,
+		"max_concurrent":     c.MaxConcurrent != 0,
+		"max_queue_wait":     c.MaxQueueWait != 0,
+		"budget":             c.Budget != 0,
+		"max_hedges":         c.MaxHedges != 0,
+		"hedge_delay":        c.HedgeDelay != 0,
+		"hedge_percentile":   c.HedgePercentile != 0,
+	}
+}
+
 // setDefaults sets default values for optional fields
 func (c *ResilienceConfig) setDefaults() {
-	// Retry defaults
-	if c.MaxRetries == 0 {
+	// Retry defaults; a preset without retries means none
+	if c.MaxRetries == 0 && c.Preset == "" {
 		c.MaxRetries = 3
 	}
 	if c.InitialDelay == 0 {
@@ -76,6 +286,9 @@ func (c *ResilienceConfig) setDefaults() {
 	if c.Multiplier == 0 {
 		c.Multiplier = 2.0
 	}
+	if c.Jitter == "" {
+		c.Jitter = JitterNone
+	}
 
 	// Circuit breaker defaults
 	if c.MaxRequests == 0 {
@@ -94,37 +307,99 @@ func (c *ResilienceConfig) setDefaults() {
 
 // Validate validates the resilience configuration
 func (c *ResilienceConfig) Validate() error {
+	if c.unknownPreset {
+		return fmt.Errorf("%s %q is not a known preset (registered: %v)",
+			c.key("preset"), c.Preset, ResiliencePresetNames())
+	}
+
 	// Validate retry settings
-	if err := ValidateRange("resilience.max_retries", c.MaxRetries, 0, 10); err != nil {
+	if err := ValidateRange(c.key("max_retries"), c.MaxRetries, 0, 10); err != nil {
 		return err
 	}
-	if err := ValidateDuration("resilience.initial_delay", c.InitialDelay); err != nil {
+	if err := ValidateDuration(c.key("initial_delay"), c.InitialDelay); err != nil {
 		return err
 	}
-	if err := ValidateDuration("resilience.max_delay", c.MaxDelay); err != nil {
+	if err := ValidateDuration(c.key("max_delay"), c.MaxDelay); err != nil {
 		return err
 	}
 	if c.MaxDelay < c.InitialDelay {
-		return fmt.Errorf("resilience.max_delay (%v) must be greater than or equal to initial_delay (%v)",
-			c.MaxDelay, c.InitialDelay)
+		return fmt.Errorf("%s (%v) must be greater than or equal to initial_delay (%v)",
+			c.key("max_delay"), c.MaxDelay, c.InitialDelay)
 	}
-	if err := ValidateRange("resilience.multiplier", c.Multiplier, 1.0, 10.0); err != nil {
+	if err := ValidateRange(c.key("multiplier"), c.Multiplier, 1.0, 10.0); err != nil {
 		return err
 	}
+	// An empty jitter means none
+	if jitters := []string{JitterNone, JitterFull, JitterEqual, JitterDecorrelated}; c.Jitter != "" && !slices.Contains(jitters, c.Jitter) {
+		return fmt.Errorf("%s must be one of: %v", c.key("jitter"), jitters)
+	}
+	if c.invalidStatusCode != "" {
+		return fmt.Errorf("%s must contain HTTP status codes, got %q", c.key("retry_status_codes"), c.invalidStatusCode)
+	}
+	for _, code := range c.RetryStatusCodes {
+		if code < 100 || code > 599 {
+			return fmt.Errorf("%s must contain HTTP status codes, got %d", c.key("retry_status_codes"), code)
+		}
+	}
 
 	// Validate circuit breaker settings
-	if err := ValidatePositive("resilience.max_requests", int(c.MaxRequests)); err != nil {
+	if err := ValidatePositive(c.key("max_requests"), int(c.MaxRequests)); err != nil {
 		return err
 	}
-	if err := ValidateDuration("resilience.interval", c.Interval); err != nil {
+	if err := ValidateDuration(c.key("interval"), c.Interval); err != nil {
 		return err
 	}
-	if err := ValidateDuration("resilience.timeout", c.Timeout); err != nil {
+	if err := ValidateDuration(c.key("timeout"), c.Timeout); err != nil {
 		return err
 	}
-	if err := ValidateRange("resilience.failure_threshold", c.FailureThreshold, 0.0, 1.0); err != nil {
+	if err := ValidateRange(c.key("failure_threshold"), c.FailureThreshold, 0.0, 1.0); err != nil {
 		return err
 	}
 
+	// Validate bulkhead settings
+	if c.MaxConcurrent < 0 {
+		return fmt.Errorf("%s must not be negative, got %d", c.key("max_concurrent"), c.MaxConcurrent)
+	}
+	if err := ValidateDuration(c.key("max_queue_wait"), c.MaxQueueWait); err != nil {
+		return err
+	}
+
+	// Validate the budget, which must leave room for at least one retry
+	if err := ValidateDuration(c.key("budget"), c.Budget); err != nil {
+		return err
+	}
+	if c.Budget > 0 && c.Budget <= c.InitialDelay {
+		return fmt.Errorf("%s (%v) must be greater than initial_delay (%v)",
+			c.key("budget"), c.Budget, c.InitialDelay)
+	}
+
+	// Validate hedging settings
+	if err := ValidateRange(c.key("max_hedges"), c.MaxHedges, 0, 10); err != nil {
+		return err
+	}
+	if err := ValidateDuration(c.key("hedge_delay"), c.HedgeDelay); err != nil {
+		return err
+	}
+	if c.HedgePercentile != 0 && (c.HedgePercentile <= 0 || c.HedgePercentile >= 1) {
+		return fmt.Errorf("%s must be between 0 and 1 exclusive, got %v", c.key("hedge_percentile"), c.HedgePercentile)
+	}
+	if c.MaxHedges > 0 {
+		if c.HedgeDelay == 0 {
+			return fmt.Errorf("%s is required when max_hedges is set", c.key("hedge_delay"))
+		}
+		if c.Budget > 0 && c.HedgeDelay >= c.Budget {
+			return fmt.Errorf("%s (%v) must be less than budget (%v)", c.key("hedge_delay"), c.HedgeDelay, c.Budget)
+		}
+	}
+
 	return nil
 }
+
+// key returns the fully qualified config key for field, used in validation
+// errors so that dependencies can be told apart.
+func (c *ResilienceConfig) key(field string) string {
+	if c.Name == "" {
+		return "resilience." + field
+	}
+	return "resilience.services." + c.Name + "." + field
+}
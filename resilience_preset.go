@@ -0,0 +1,135 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Built-in resilience presets, selected with RESILIENCE_PRESET
+const (
+	// PresetInteractive fails fast for calls a user is waiting on: one quick
+	// retry and a 2s budget
+	PresetInteractive = "interactive"
+	// PresetBackground is patient, for jobs and queue consumers: up to 8
+	// retries with decorrelated jitter and delays up to 2m
+	PresetBackground = "background"
+	// PresetCritical retries quickly within a 30s budget and hedges slow calls,
+	// for dependencies a request cannot complete without
+	PresetCritical = "critical"
+	// PresetNone disables retries and hedging
+	PresetNone = "none"
+)
+
+var (
+	resiliencePresetsMu sync.RWMutex
+	resiliencePresets   = map[string]ResilienceConfig{
+		PresetInteractive: {
+			MaxRetries:       1,
+			InitialDelay:     50 * time.Millisecond,
+			MaxDelay:         200 * time.Millisecond,
+			Multiplier:       2,
+			Jitter:           JitterFull,
+			MaxRequests:      5,
+			Interval:         10 * time.Second,
+			Timeout:          15 * time.Second,
+			FailureThreshold: 0.5,
+			Budget:           2 * time.Second,
+		},
+		PresetBackground: {
+			MaxRetries:       8,
+			InitialDelay:     time.Second,
+			MaxDelay:         2 * time.Minute,
+			Multiplier:       2,
+			Jitter:           JitterDecorrelated,
+			MaxRequests:      10,
+			Interval:         time.Minute,
+			Timeout:          time.Minute,
+			FailureThreshold: 0.8,
+		},
+		PresetCritical: {
+			MaxRetries:       5,
+			InitialDelay:     100 * time.Millisecond,
+			MaxDelay:         5 * time.Second,
+			Multiplier:       2,
+			Jitter:           JitterEqual,
+			MaxRequests:      20,
+			Interval:         30 * time.Second,
+			Timeout:          30 * time.Second,
+			FailureThreshold: 0.7,
+			Budget:           30 * time.Second,
+			MaxHedges:        1,
+			HedgeDelay:       500 * time.Millisecond,
+			HedgePercentile:  0.95,
+		},
+		PresetNone: {
+			MaxRetries: 0,
+			Jitter:     JitterNone,
+		},
+	}
+)
+
+// RegisterResiliencePreset adds or replaces a preset. Fields left at zero take
+// the usual defaults, except MaxRetries: a preset with no retries means none.
+func RegisterResiliencePreset(name string, preset ResilienceConfig) error {
+	if name == "" {
+		return errors.New("resilience preset name is required")
+	}
+	check := preset
+	check.Preset = name
+	check.setDefaults()
+	if err := check.Validate(); err != nil {
+		return fmt.Errorf("resilience preset %s: %w", name, err)
+	}
+
+	resiliencePresetsMu.Lock()
+	defer resiliencePresetsMu.Unlock()
+	resiliencePresets[name] = preset
+	return nil
+}
+
+// ResiliencePreset returns the preset registered under name
+func ResiliencePreset(name string) (ResilienceConfig, bool) {
+	resiliencePresetsMu.RLock()
+	defer resiliencePresetsMu.RUnlock()
+	preset, ok := resiliencePresets[name]
+	return preset, ok
+}
+
+// ResiliencePresetNames returns the sorted names of the registered presets
+func ResiliencePresetNames() []string {
+	resiliencePresetsMu.RLock()
+	defer resiliencePresetsMu.RUnlock()
+
+	names := make([]string, 0, len(resiliencePresets))
+	for name := range resiliencePresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// resiliencePreset returns the preset called name: the registered preset,
+// overridden by a resilience.presets.<name> block in the config file
+func (s *Standard) resiliencePreset(name string) ResilienceConfig {
+	preset, ok := ResiliencePreset(name)
+	if key := "resilience.presets." + name; s.viper.IsSet(key) {
+		preset = loadResilienceConfig(s, key+".", preset)
+		ok = true
+	}
+	preset.Name = ""
+	preset.Preset = name
+	preset.unknownPreset = !ok
+
+	// Every field the preset gives a value supplies it, as does MaxRetries,
+	// since a preset without retries means none
+	preset.sources = make(map[string]string)
+	for key, set := range preset.fieldsSet() {
+		if ok && (set || key == "max_retries") {
+			preset.sources[key] = SourcePreset
+		}
+	}
+	return preset
+}
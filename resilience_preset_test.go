@@ -0,0 +1,217 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	config "github.com/JohnPlummer/jp-go-config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResiliencePresets(t *testing.T) {
+	t.Run("built-in presets are valid", func(t *testing.T) {
+		for _, name := range []string{
+			config.PresetInteractive, config.PresetBackground, config.PresetCritical, config.PresetNone,
+		} {
+			t.Run(name, func(t *testing.T) {
+				os.Setenv("RESILIENCE_PRESET", name)
+				defer os.Unsetenv("RESILIENCE_PRESET")
+
+				std, err := config.NewStandard()
+				require.NoError(t, err)
+				cfg := config.ResilienceConfigFromViper(std)
+
+				assert.Equal(t, name, cfg.Preset)
+				require.NoError(t, cfg.Validate())
+			})
+		}
+	})
+
+	t.Run("applies the preset before defaults", func(t *testing.T) {
+		os.Setenv("RESILIENCE_PRESET", "background")
+		defer os.Unsetenv("RESILIENCE_PRESET")
+
+		std, err := config.NewStandard()
+		require.NoError(t, err)
+		cfg := config.ResilienceConfigFromViper(std)
+
+		assert.Equal(t, 8, cfg.MaxRetries)
+		assert.Equal(t, 2*time.Minute, cfg.MaxDelay)
+		assert.Equal(t, config.JitterDecorrelated, cfg.Jitter)
+		assert.Equal(t, 0.8, cfg.FailureThreshold)
+	})
+
+	t.Run("none disables retries", func(t *testing.T) {
+		os.Setenv("RESILIENCE_PRESET", "none")
+		defer os.Unsetenv("RESILIENCE_PRESET")
+
+		std, err := config.NewStandard()
+		require.NoError(t, err)
+		cfg := config.ResilienceConfigFromViper(std)
+
+		assert.Equal(t, 0, cfg.MaxRetries)
+		assert.Equal(t, time.Second, cfg.InitialDelay)
+	})
+
+	t.Run("explicit fields override the preset", func(t *testing.T) {
+		os.Setenv("RESILIENCE_PRESET", "interactive")
+		os.Setenv("RESILIENCE_MAX_RETRIES", "2")
+		defer os.Unsetenv("RESILIENCE_PRESET")
+		defer os.Unsetenv("RESILIENCE_MAX_RETRIES")
+
+		std, err := config.NewStandard()
+		require.NoError(t, err)
+		cfg := config.ResilienceConfigFromViper(std)
+
+		assert.Equal(t, 2, cfg.MaxRetries)
+		assert.Equal(t, 50*time.Millisecond, cfg.InitialDelay)
+		assert.Equal(t, 2*time.Second, cfg.Budget)
+	})
+
+	t.Run("reports where each field came from", func(t *testing.T) {
+		os.Setenv("RESILIENCE_PRESET", "interactive")
+		os.Setenv("RESILIENCE_MAX_RETRIES", "2")
+		defer os.Unsetenv("RESILIENCE_PRESET")
+		defer os.Unsetenv("RESILIENCE_MAX_RETRIES")
+
+		std, err := config.NewStandard()
+		require.NoError(t, err)
+		cfg := config.ResilienceConfigFromViper(std)
+		sources := cfg.Sources()
+
+		assert.Equal(t, config.SourcePreset, sources["initial_delay"])
+		assert.Equal(t, config.SourcePreset, sources["budget"])
+		assert.Equal(t, config.SourceExplicit, sources["max_retries"])
+		assert.Equal(t, config.SourceDefault, sources["max_hedges"])
+	})
+
+	t.Run("rejects unknown presets", func(t *testing.T) {
+		os.Setenv("RESILIENCE_PRESET", "reckless")
+		defer os.Unsetenv("RESILIENCE_PRESET")
+
+		std, err := config.NewStandard()
+		require.NoError(t, err)
+		cfg := config.ResilienceConfigFromViper(std)
+
+		err = cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `resilience.preset "reckless" is not a known preset`)
+	})
+
+	t.Run("without a preset the defaults apply", func(t *testing.T) {
+		std, err := config.NewStandard()
+		require.NoError(t, err)
+		cfg := config.ResilienceConfigFromViper(std)
+
+		assert.Empty(t, cfg.Preset)
+		assert.Equal(t, 3, cfg.MaxRetries)
+	})
+}
+
+func TestRegisterResiliencePreset(t *testing.T) {
+	t.Run("registers presets in code", func(t *testing.T) {
+		require.NoError(t, config.RegisterResiliencePreset("batch-import", config.ResilienceConfig{
+			MaxRetries:   6,
+			InitialDelay: 5 * time.Second,
+			MaxDelay:     time.Minute,
+		}))
+		assert.Contains(t, config.ResiliencePresetNames(), "batch-import")
+
+		os.Setenv("RESILIENCE_PRESET", "batch-import")
+		defer os.Unsetenv("RESILIENCE_PRESET")
+
+		std, err := config.NewStandard()
+		require.NoError(t, err)
+		cfg := config.ResilienceConfigFromViper(std)
+
+		assert.Equal(t, 6, cfg.MaxRetries)
+		assert.Equal(t, 5*time.Second, cfg.InitialDelay)
+		assert.Equal(t, 2.0, cfg.Multiplier)
+		require.NoError(t, cfg.Validate())
+	})
+
+	t.Run("rejects invalid presets", func(t *testing.T) {
+		err := config.RegisterResiliencePreset("broken", config.ResilienceConfig{MaxRetries: 20})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "resilience preset broken")
+
+		_, ok := config.ResiliencePreset("broken")
+		assert.False(t, ok)
+	})
+
+	t.Run("requires a name", func(t *testing.T) {
+		assert.Error(t, config.RegisterResiliencePreset("", config.ResilienceConfig{}))
+	})
+}
+
+func TestResiliencePresetsFromFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	configFile := filepath.Join(tmpDir, "config.yaml")
+	require.NoError(t, os.WriteFile(configFile, []byte(`
+resilience:
+  preset: background
+  timeout: 45s
+  presets:
+    nightly:
+      max_retries: 10
+      initial_delay: 30s
+      max_delay: 10m
+    critical:
+      max_hedges: 2
+  services:
+    reports:
+      preset: nightly
+    payments:
+      preset: critical
+      max_retries: 2
+`), 0o600))
+
+	std, err := config.NewStandard(config.WithConfigFile(configFile))
+	require.NoError(t, err)
+
+	t.Run("global preset with overrides", func(t *testing.T) {
+		cfg := config.ResilienceConfigFromViper(std)
+
+		assert.Equal(t, "background", cfg.Preset)
+		assert.Equal(t, 8, cfg.MaxRetries)
+		assert.Equal(t, 45*time.Second, cfg.Timeout)
+	})
+
+	t.Run("services inherit the global preset", func(t *testing.T) {
+		cfg := config.ResilienceConfigFor(std, "search")
+
+		assert.Equal(t, "background", cfg.Preset)
+		assert.Equal(t, 8, cfg.MaxRetries)
+		assert.Equal(t, 45*time.Second, cfg.Timeout)
+		assert.Equal(t, config.SourcePreset, cfg.Sources()["max_retries"])
+		assert.Equal(t, config.SourceExplicit, cfg.Sources()["timeout"], "set in the global block")
+	})
+
+	t.Run("services select presets defined in the file", func(t *testing.T) {
+		cfg := config.ResilienceConfigFor(std, "reports")
+
+		assert.Equal(t, "nightly", cfg.Preset)
+		assert.Equal(t, 10, cfg.MaxRetries)
+		assert.Equal(t, 10*time.Minute, cfg.MaxDelay)
+		assert.Equal(t, 60*time.Second, cfg.Timeout)
+		require.NoError(t, cfg.Validate())
+	})
+
+	t.Run("file blocks extend built-in presets", func(t *testing.T) {
+		cfg := config.ResilienceConfigFor(std, "payments")
+
+		assert.Equal(t, "critical", cfg.Preset)
+		assert.Equal(t, 2, cfg.MaxRetries)
+		assert.Equal(t, 2, cfg.MaxHedges)
+		assert.Equal(t, 100*time.Millisecond, cfg.InitialDelay)
+		require.NoError(t, cfg.Validate())
+
+		sources := cfg.Sources()
+		assert.Equal(t, config.SourceExplicit, sources["max_retries"])
+		assert.Equal(t, config.SourcePreset, sources["max_hedges"], "set in the preset block")
+		assert.Equal(t, config.SourcePreset, sources["initial_delay"])
+	})
+}
@@ -2,6 +2,7 @@ package config_test
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -222,3 +223,132 @@ func TestResilienceConfig_Validate(t *testing.T) {
 		assert.Contains(t, err.Error(), "resilience.failure_threshold must be between")
 	})
 }
+
+func TestResilienceConfigFor(t *testing.T) {
+	tmpDir := t.TempDir()
+	configFile := filepath.Join(tmpDir, "config.yaml")
+	require.NoError(t, os.WriteFile(configFile, []byte(`
+resilience:
+  max_retries: 2
+  timeout: 30s
+  services:
+    openai:
+      max_retries: 5
+      timeout: 60s
+    cache:
+      max_retries: 1
+      initial_delay: 10ms
+      max_delay: 5ms
+`), 0o600))
+
+	t.Run("inherits from the global block", func(t *testing.T) {
+		std, err := config.NewStandard(config.WithConfigFile(configFile))
+		require.NoError(t, err)
+
+		cfg := config.ResilienceConfigFor(std, "openai")
+
+		assert.Equal(t, "openai", cfg.Name)
+		assert.Equal(t, 5, cfg.MaxRetries)
+		assert.Equal(t, 60*time.Second, cfg.Timeout)
+		assert.Equal(t, time.Second, cfg.InitialDelay)
+		assert.Equal(t, 10*time.Second, cfg.Interval)
+		require.NoError(t, cfg.Validate())
+	})
+
+	t.Run("environment variables override the service block", func(t *testing.T) {
+		os.Setenv("RESILIENCE_OPENAI_TIMEOUT", "90s")
+		defer os.Unsetenv("RESILIENCE_OPENAI_TIMEOUT")
+
+		std, err := config.NewStandard(config.WithConfigFile(configFile))
+		require.NoError(t, err)
+
+		cfg := config.ResilienceConfigFor(std, "openai")
+
+		assert.Equal(t, 90*time.Second, cfg.Timeout)
+	})
+
+	t.Run("unconfigured services use the global block", func(t *testing.T) {
+		std, err := config.NewStandard(config.WithConfigFile(configFile))
+		require.NoError(t, err)
+
+		cfg := config.ResilienceConfigFor(std, "search")
+
+		assert.Equal(t, 2, cfg.MaxRetries)
+		assert.Equal(t, 30*time.Second, cfg.Timeout)
+	})
+
+	t.Run("validates each service with its own keys", func(t *testing.T) {
+		std, err := config.NewStandard(config.WithConfigFile(configFile))
+		require.NoError(t, err)
+
+		cfg := config.ResilienceConfigFor(std, "cache")
+
+		err = cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "resilience.services.cache.max_delay")
+	})
+
+	t.Run("lists configured services", func(t *testing.T) {
+		os.Setenv("RESILIENCE_SERVICES", "search,openai")
+		defer os.Unsetenv("RESILIENCE_SERVICES")
+
+		std, err := config.NewStandard(config.WithConfigFile(configFile))
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"cache", "openai", "search"}, config.ResilienceServiceNames(std))
+	})
+}
+
+func TestResilienceConfig_ValidateLimits(t *testing.T) {
+	valid := func() config.ResilienceConfig {
+		return config.ResilienceConfig{
+			MaxRetries:       3,
+			InitialDelay:     time.Second,
+			MaxDelay:         30 * time.Second,
+			Multiplier:       2,
+			MaxRequests:      10,
+			Interval:         10 * time.Second,
+			Timeout:          60 * time.Second,
+			FailureThreshold: 0.6,
+		}
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(cfg *config.ResilienceConfig)
+		wantErr string
+	}{
+		{name: "bulkhead, budget and hedging pass", modify: func(cfg *config.ResilienceConfig) {
+			cfg.MaxConcurrent = 10
+			cfg.MaxQueueWait = time.Second
+			cfg.Budget = 2 * time.Minute
+			cfg.MaxHedges = 1
+			cfg.HedgeDelay = 500 * time.Millisecond
+			cfg.HedgePercentile = 0.95
+		}},
+		{name: "negative max concurrent fails", modify: func(cfg *config.ResilienceConfig) { cfg.MaxConcurrent = -1 }, wantErr: "resilience.max_concurrent"},
+		{name: "budget within initial delay fails", modify: func(cfg *config.ResilienceConfig) { cfg.Budget = 500 * time.Millisecond }, wantErr: "resilience.budget (500ms) must be greater than initial_delay (1s)"},
+		{name: "hedging requires a delay", modify: func(cfg *config.ResilienceConfig) { cfg.MaxHedges = 1 }, wantErr: "resilience.hedge_delay is required"},
+		{name: "hedge delay beyond budget fails", modify: func(cfg *config.ResilienceConfig) {
+			cfg.Budget = 5 * time.Second
+			cfg.MaxHedges = 1
+			cfg.HedgeDelay = 10 * time.Second
+		}, wantErr: "must be less than budget"},
+		{name: "percentile out of range fails", modify: func(cfg *config.ResilienceConfig) { cfg.HedgePercentile = 95 }, wantErr: "resilience.hedge_percentile"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid()
+			tt.modify(&cfg)
+
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,190 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// defaultRetryStatusCodes are the responses retried when RetryStatusCodes is empty
+var defaultRetryStatusCodes = []int{
+	http.StatusTooManyRequests, http.StatusBadGateway,
+	http.StatusServiceUnavailable, http.StatusGatewayTimeout,
+}
+
+// retryStatusCodes returns RetryStatusCodes or the defaults
+func (c *ResilienceConfig) retryStatusCodes() []int {
+	if len(c.RetryStatusCodes) == 0 {
+		return defaultRetryStatusCodes
+	}
+	return c.RetryStatusCodes
+}
+
+// RoundTripper wraps base with retries: up to MaxRetries further attempts with
+// exponential backoff from InitialDelay, multiplied by Multiplier, capped at
+// MaxDelay and jittered as configured (see ResilienceConfig.Backoff). A
+// Retry-After header on the response is honoured up to MaxDelay.
+//
+// Only requests that are safe to repeat are retried: idempotent methods, or
+// any method carrying an Idempotency-Key header, and only when the body can be
+// replayed through GetBody. Network errors and responses with one of
+// RetryStatusCodes (default 429, 502, 503 and 504) are retried.
+//
+// When FailureThreshold is set, each host also gets its own CircuitBreaker.
+// Network errors and retryable 5xx responses count as failures, and while a
+// host's circuit is open requests to it fail with an error wrapping
+// ErrCircuitOpen without being sent.
+func (c *ResilienceConfig) RoundTripper(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &retryTransport{base: base, config: *c}
+}
+
+// retryTransport retries requests according to a ResilienceConfig
+type retryTransport struct {
+	base   http.RoundTripper
+	config ResilienceConfig
+
+	mu       sync.Mutex
+	breakers map[string]*CircuitBreaker
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !retryable(req) {
+		return t.send(req)
+	}
+
+	backoff := t.config.Backoff()
+	for attempt := 0; ; attempt++ {
+		attemptReq := req
+		if attempt > 0 && req.Body != nil && req.Body != http.NoBody {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			attemptReq = req.Clone(req.Context())
+			attemptReq.Body = body
+		}
+
+		resp, err := t.send(attemptReq)
+		if errors.Is(err, ErrCircuitOpen) || !t.shouldRetry(resp, err) {
+			return resp, err
+		}
+		delay, ok := backoff.Next()
+		if !ok {
+			return resp, err
+		}
+
+		if resp != nil {
+			if after, ok := retryAfter(resp); ok {
+				delay = min(after, t.config.MaxDelay)
+			}
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			_ = resp.Body.Close()
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// send makes one round trip through the host's circuit breaker, if any
+func (t *retryTransport) send(req *http.Request) (*http.Response, error) {
+	breaker := t.breaker(req.URL.Host)
+	if breaker == nil {
+		return t.base.RoundTrip(req)
+	}
+
+	var resp *http.Response
+	err := breaker.Execute(req.Context(), func(context.Context) error {
+		var err error
+		resp, err = t.base.RoundTrip(req)
+		if err == nil && resp.StatusCode >= 500 && t.retryStatus(resp.StatusCode) {
+			return errServerFailure
+		}
+		return err
+	})
+	switch {
+	case errors.Is(err, ErrCircuitOpen), errors.Is(err, ErrTooManyRequests):
+		return nil, fmt.Errorf("%s: %w", req.URL.Host, err)
+	case errors.Is(err, errServerFailure):
+		return resp, nil
+	}
+	return resp, err
+}
+
+// errServerFailure records a retryable 5xx response as a breaker failure
+var errServerFailure = errors.New("server failure")
+
+// breaker returns the circuit breaker for host, or nil when breaking is off
+func (t *retryTransport) breaker(host string) *CircuitBreaker {
+	if t.config.FailureThreshold <= 0 {
+		return nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.breakers == nil {
+		t.breakers = make(map[string]*CircuitBreaker)
+	}
+	breaker, ok := t.breakers[host]
+	if !ok {
+		breaker = t.config.NewCircuitBreaker()
+		t.breakers[host] = breaker
+	}
+	return breaker
+}
+
+// retryable reports whether req can safely be sent more than once
+func retryable(req *http.Request) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace,
+		http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return req.Header.Get("Idempotency-Key") != ""
+}
+
+// shouldRetry reports whether a round trip result is worth retrying
+func (t *retryTransport) shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	return t.retryStatus(resp.StatusCode)
+}
+
+// retryStatus reports whether a response status is retried
+func (t *retryTransport) retryStatus(code int) bool {
+	return slices.Contains(t.config.retryStatusCodes(), code)
+}
+
+// retryAfter parses a Retry-After header given in seconds or as an HTTP date
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(time.Until(at), 0), true
+	}
+	return 0, false
+}
@@ -0,0 +1,254 @@
+package config_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	config "github.com/JohnPlummer/jp-go-config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func transportResilience() config.ResilienceConfig {
+	return config.ResilienceConfig{
+		MaxRetries:   3,
+		InitialDelay: time.Millisecond,
+		MaxDelay:     10 * time.Millisecond,
+		Multiplier:   2,
+	}
+}
+
+// flakyServer answers the first failures requests with status and counts calls
+func flakyServer(failures int32, status int) (*httptest.Server, *atomic.Int32) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if calls.Add(1) <= failures {
+			w.WriteHeader(status)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	return srv, &calls
+}
+
+func TestResilienceConfig_RoundTripper(t *testing.T) {
+	t.Run("retries default status codes only", func(t *testing.T) {
+		tests := []struct {
+			status int
+			calls  int32
+		}{
+			{http.StatusTooManyRequests, 2},
+			{http.StatusBadGateway, 2},
+			{http.StatusServiceUnavailable, 2},
+			{http.StatusGatewayTimeout, 2},
+			{http.StatusInternalServerError, 1},
+			{http.StatusNotFound, 1},
+		}
+
+		for _, tt := range tests {
+			t.Run(http.StatusText(tt.status), func(t *testing.T) {
+				srv, calls := flakyServer(1, tt.status)
+				defer srv.Close()
+
+				resilience := transportResilience()
+				client := &http.Client{Transport: resilience.RoundTripper(nil)}
+				resp, err := client.Get(srv.URL)
+				require.NoError(t, err)
+				resp.Body.Close()
+
+				assert.Equal(t, tt.calls, calls.Load())
+			})
+		}
+	})
+
+	t.Run("retries configured status codes", func(t *testing.T) {
+		srv, calls := flakyServer(2, http.StatusInternalServerError)
+		defer srv.Close()
+
+		resilience := transportResilience()
+		resilience.RetryStatusCodes = []int{http.StatusInternalServerError}
+		client := &http.Client{Transport: resilience.RoundTripper(nil)}
+
+		resp, err := client.Get(srv.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, int32(3), calls.Load())
+	})
+
+	t.Run("honours Retry-After", func(t *testing.T) {
+		var calls atomic.Int32
+		var first time.Time
+		var waited time.Duration
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			if calls.Add(1) == 1 {
+				first = time.Now()
+				w.Header().Set("Retry-After", "1")
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			waited = time.Since(first)
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer srv.Close()
+
+		resilience := transportResilience()
+		resilience.MaxDelay = 2 * time.Second
+		client := &http.Client{Transport: resilience.RoundTripper(nil)}
+
+		resp, err := client.Get(srv.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.GreaterOrEqual(t, waited, 900*time.Millisecond)
+	})
+
+	t.Run("rewinds request bodies", func(t *testing.T) {
+		var calls atomic.Int32
+		var bodies []string
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			bodies = append(bodies, string(body))
+			if calls.Add(1) < 3 {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer srv.Close()
+
+		resilience := transportResilience()
+		client := &http.Client{Transport: resilience.RoundTripper(nil)}
+
+		req, err := http.NewRequest(http.MethodPut, srv.URL, strings.NewReader("payload"))
+		require.NoError(t, err)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, []string{"payload", "payload", "payload"}, bodies)
+	})
+
+	t.Run("does not retry bodies that cannot be rewound", func(t *testing.T) {
+		srv, calls := flakyServer(1, http.StatusServiceUnavailable)
+		defer srv.Close()
+
+		resilience := transportResilience()
+		client := &http.Client{Transport: resilience.RoundTripper(nil)}
+
+		req, err := http.NewRequest(http.MethodPut, srv.URL, io.NopCloser(strings.NewReader("payload")))
+		require.NoError(t, err)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("retries connection errors", func(t *testing.T) {
+		srv := httptest.NewServer(http.NotFoundHandler())
+		srv.Close()
+
+		resilience := transportResilience()
+		var attempts atomic.Int32
+		base := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			attempts.Add(1)
+			return http.DefaultTransport.RoundTrip(req)
+		})
+		client := &http.Client{Transport: resilience.RoundTripper(base)}
+
+		_, err := client.Get(srv.URL)
+		require.Error(t, err)
+		assert.Equal(t, int32(4), attempts.Load())
+	})
+}
+
+func TestResilienceConfig_RoundTripperCircuitBreaker(t *testing.T) {
+	resilience := transportResilience()
+	resilience.MaxRetries = 0
+	resilience.FailureThreshold = 0.5
+	resilience.MaxRequests = 1
+	resilience.Interval = time.Minute
+	resilience.Timeout = time.Minute
+	client := &http.Client{Transport: resilience.RoundTripper(nil)}
+
+	failing, failingCalls := flakyServer(100, http.StatusServiceUnavailable)
+	defer failing.Close()
+	healthy, healthyCalls := flakyServer(0, http.StatusServiceUnavailable)
+	defer healthy.Close()
+
+	for range 5 {
+		resp, err := client.Get(failing.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	}
+
+	_, err := client.Get(failing.URL)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, config.ErrCircuitOpen))
+	assert.Equal(t, int32(5), failingCalls.Load())
+
+	resp, err := client.Get(healthy.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int32(1), healthyCalls.Load())
+
+	t.Run("does not count 429 as a failure", func(t *testing.T) {
+		throttled, calls := flakyServer(100, http.StatusTooManyRequests)
+		defer throttled.Close()
+
+		for range 6 {
+			resp, err := client.Get(throttled.URL)
+			require.NoError(t, err)
+			resp.Body.Close()
+		}
+		assert.Equal(t, int32(6), calls.Load())
+	})
+}
+
+func TestResilienceConfig_RetryStatusCodes(t *testing.T) {
+	t.Run("reads a comma-separated list from the environment", func(t *testing.T) {
+		os.Setenv("RESILIENCE_RETRY_STATUS_CODES", "500, 503")
+		defer os.Unsetenv("RESILIENCE_RETRY_STATUS_CODES")
+
+		std, err := config.NewStandard()
+		require.NoError(t, err)
+		cfg := config.ResilienceConfigFromViper(std)
+
+		require.NoError(t, cfg.Validate())
+		assert.Equal(t, []int{500, 503}, cfg.RetryStatusCodes)
+	})
+
+	t.Run("rejects invalid status codes", func(t *testing.T) {
+		os.Setenv("RESILIENCE_RETRY_STATUS_CODES", "503,oops")
+		defer os.Unsetenv("RESILIENCE_RETRY_STATUS_CODES")
+
+		std, err := config.NewStandard()
+		require.NoError(t, err)
+		cfg := config.ResilienceConfigFromViper(std)
+
+		err = cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `resilience.retry_status_codes must contain HTTP status codes, got "oops"`)
+	})
+}
+
+// roundTripFunc adapts a function to http.RoundTripper
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
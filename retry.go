@@ -0,0 +1,154 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Retry calls fn until it succeeds, up to MaxRetries further times, waiting
+// between attempts as described by Backoff.
+//
+// An error is retried unless it is wrapped with Permanent, is the context's
+// own error, or Retryable is set and returns false for it. A Retry-After hint
+// carried by the error (see WithRetryAfter) replaces the backoff delay, capped
+// at MaxDelay. Retry does not sleep past the context deadline: when the next
+// delay would end after it, Retry gives up straight away. When Budget is set,
+// the deadline is at most Budget after the first attempt starts.
+//
+// On failure the returned *RetryError wraps the error of every attempt, so
+// errors.Is and errors.As see each of them.
+func (c *ResilienceConfig) Retry(ctx context.Context, fn func(ctx context.Context) error) error {
+	ctx, cancel := c.BudgetContext(ctx)
+	defer cancel()
+	backoff := c.Backoff()
+
+	var errs []error
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, err)
+
+		if !c.retryable(ctx, err) {
+			return &RetryError{Attempts: attempt, Errors: errs}
+		}
+		delay, ok := backoff.Next()
+		if !ok {
+			return &RetryError{Attempts: attempt, Errors: errs}
+		}
+		if after, ok := retryAfterHint(err); ok {
+			delay = min(after, c.MaxDelay)
+		}
+
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			errs = append(errs, fmt.Errorf("next attempt in %v would pass the deadline: %w", delay, context.DeadlineExceeded))
+			return &RetryError{Attempts: attempt, Errors: errs}
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			errs = append(errs, ctx.Err())
+			return &RetryError{Attempts: attempt, Errors: errs}
+		case <-timer.C:
+		}
+	}
+}
+
+// BudgetContext derives a context whose deadline is Budget from now, or the
+// parent's deadline if that is sooner. Without a Budget it returns a
+// cancelable copy of ctx.
+func (c *ResilienceConfig) BudgetContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.Budget <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, c.Budget)
+}
+
+// retryable classifies err for Retry
+func (c *ResilienceConfig) retryable(ctx context.Context, err error) bool {
+	var permanent *permanentError
+	if errors.As(err, &permanent) {
+		return false
+	}
+	if ctx.Err() != nil && errors.Is(err, ctx.Err()) {
+		return false
+	}
+	if c.Retryable != nil {
+		return c.Retryable(err)
+	}
+	return true
+}
+
+// RetryError is returned by Retry when every attempt failed. It wraps the
+// error of each attempt, followed by the context error when Retry stopped
+// because of the context.
+type RetryError struct {
+	Attempts int
+	Errors   []error
+}
+
+// Error implements error, reporting the last attempt's error
+func (e *RetryError) Error() string {
+	msg := fmt.Sprintf("failed after %d attempts: %v", e.Attempts, e.Errors[e.Attempts-1])
+	if len(e.Errors) > e.Attempts {
+		msg += " (" + e.Errors[len(e.Errors)-1].Error() + ")"
+	}
+	return msg
+}
+
+// Unwrap returns the errors of all attempts
+func (e *RetryError) Unwrap() []error {
+	return e.Errors
+}
+
+// permanentError marks an error that must not be retried
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps err so that Retry returns it without further attempts. It
+// returns nil when err is nil.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// retryAfterError carries a server-provided delay before the next attempt
+type retryAfterError struct {
+	err   error
+	delay time.Duration
+}
+
+func (e *retryAfterError) Error() string             { return e.err.Error() }
+func (e *retryAfterError) Unwrap() error             { return e.err }
+func (e *retryAfterError) RetryAfter() time.Duration { return e.delay }
+
+// WithRetryAfter wraps err with a hint to wait delay before the next attempt,
+// such as the Retry-After header of a 429 or 503 response. Errors of other
+// types can carry a hint by implementing RetryAfter() time.Duration. It
+// returns nil when err is nil.
+func WithRetryAfter(err error, delay time.Duration) error {
+	if err == nil {
+		return nil
+	}
+	return &retryAfterError{err: err, delay: delay}
+}
+
+// retryAfterHint returns the Retry-After hint carried by err, if any
+func retryAfterHint(err error) (time.Duration, bool) {
+	var hint interface{ RetryAfter() time.Duration }
+	if errors.As(err, &hint) {
+		return max(hint.RetryAfter(), 0), true
+	}
+	return 0, false
+}
@@ -0,0 +1,158 @@
+package config_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	config "github.com/JohnPlummer/jp-go-config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResilienceConfig_Retry(t *testing.T) {
+	errTransient := errors.New("transient")
+	errFatal := errors.New("fatal")
+
+	resilience := func() config.ResilienceConfig {
+		return config.ResilienceConfig{
+			MaxRetries:   3,
+			InitialDelay: time.Millisecond,
+			MaxDelay:     10 * time.Millisecond,
+			Multiplier:   2,
+		}
+	}
+
+	t.Run("retries until success", func(t *testing.T) {
+		cfg := resilience()
+		calls := 0
+
+		err := cfg.Retry(context.Background(), func(context.Context) error {
+			calls++
+			if calls < 3 {
+				return errTransient
+			}
+			return nil
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("wraps every attempt error when retries run out", func(t *testing.T) {
+		cfg := resilience()
+		calls := 0
+
+		err := cfg.Retry(context.Background(), func(context.Context) error {
+			calls++
+			return errTransient
+		})
+
+		var retryErr *config.RetryError
+		require.ErrorAs(t, err, &retryErr)
+		assert.Equal(t, 4, calls)
+		assert.Equal(t, 4, retryErr.Attempts)
+		assert.Len(t, retryErr.Errors, 4)
+		assert.ErrorIs(t, err, errTransient)
+		assert.Contains(t, err.Error(), "failed after 4 attempts: transient")
+	})
+
+	t.Run("stops on permanent errors", func(t *testing.T) {
+		cfg := resilience()
+		calls := 0
+
+		err := cfg.Retry(context.Background(), func(context.Context) error {
+			calls++
+			return config.Permanent(errFatal)
+		})
+
+		assert.Equal(t, 1, calls)
+		assert.ErrorIs(t, err, errFatal)
+	})
+
+	t.Run("uses the retryable classifier", func(t *testing.T) {
+		cfg := resilience()
+		cfg.Retryable = func(err error) bool { return errors.Is(err, errTransient) }
+		calls := 0
+
+		err := cfg.Retry(context.Background(), func(context.Context) error {
+			calls++
+			if calls == 1 {
+				return errTransient
+			}
+			return errFatal
+		})
+
+		assert.Equal(t, 2, calls)
+		assert.ErrorIs(t, err, errTransient)
+		assert.ErrorIs(t, err, errFatal)
+	})
+
+	t.Run("honours retry after hints", func(t *testing.T) {
+		cfg := resilience()
+		cfg.MaxDelay = 50 * time.Millisecond
+		calls := 0
+		start := time.Now()
+
+		err := cfg.Retry(context.Background(), func(context.Context) error {
+			calls++
+			if calls == 1 {
+				return config.WithRetryAfter(errTransient, 30*time.Millisecond)
+			}
+			return nil
+		})
+
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, time.Since(start), 30*time.Millisecond)
+	})
+
+	t.Run("does not sleep past the context deadline", func(t *testing.T) {
+		cfg := resilience()
+		cfg.InitialDelay = time.Second
+		cfg.MaxDelay = time.Second
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+
+		err := cfg.Retry(ctx, func(context.Context) error {
+			return errTransient
+		})
+
+		assert.Less(t, time.Since(start), 100*time.Millisecond)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.ErrorIs(t, err, errTransient)
+	})
+
+	t.Run("stops when the budget runs out", func(t *testing.T) {
+		cfg := resilience()
+		cfg.MaxRetries = 10
+		cfg.InitialDelay = 20 * time.Millisecond
+		cfg.MaxDelay = 20 * time.Millisecond
+		cfg.Budget = 50 * time.Millisecond
+		calls := 0
+
+		err := cfg.Retry(context.Background(), func(context.Context) error {
+			calls++
+			return errTransient
+		})
+
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Less(t, calls, 5)
+	})
+
+	t.Run("stops when the context is cancelled", func(t *testing.T) {
+		cfg := resilience()
+		ctx, cancel := context.WithCancel(context.Background())
+		calls := 0
+
+		err := cfg.Retry(ctx, func(ctx context.Context) error {
+			calls++
+			cancel()
+			return ctx.Err()
+		})
+
+		assert.Equal(t, 1, calls)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
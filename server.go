@@ -1,42 +1,76 @@
 package config
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
 	"time"
 )
 
 // ServerConfig holds HTTP server configuration
 type ServerConfig struct {
-	Host         string        `mapstructure:"host"`
-	Port         int           `mapstructure:"port"`
-	ReadTimeout  time.Duration `mapstructure:"read_timeout"`
-	WriteTimeout time.Duration `mapstructure:"write_timeout"`
-	IdleTimeout  time.Duration `mapstructure:"idle_timeout"`
+	Host              string        `mapstructure:"host"`
+	Port              int           `mapstructure:"port"`
+	ListenAddr        string        `mapstructure:"listen"`
+	SocketMode        string        `mapstructure:"socket_mode"`
+	ReadTimeout       time.Duration `mapstructure:"read_timeout"`
+	ReadHeaderTimeout time.Duration `mapstructure:"read_header_timeout"`
+	WriteTimeout      time.Duration `mapstructure:"write_timeout"`
+	IdleTimeout       time.Duration `mapstructure:"idle_timeout"`
+	MaxHeaderBytes    int           `mapstructure:"max_header_bytes"`
+	ShutdownTimeout   time.Duration `mapstructure:"shutdown_timeout"`
+
+	// TLS terminates HTTPS in-process when a certificate and key are configured
+	TLS ServerTLSConfig `mapstructure:"tls"`
+
+	// Policy holds CORS, trusted proxy and request body limits, applied by
+	// Policy.Middleware
+	Policy HTTPPolicyConfig `mapstructure:",squash"`
+
+	// OnListen, when set, is called by Run with the address it bound before
+	// serving, such as the port picked for port 0
+	OnListen func(addr net.Addr) `mapstructure:"-"`
+
+	// keyPrefix is the config key prefix used in validation errors (default: server)
+	keyPrefix string
 }
 
 // ServerConfigFromViper creates a ServerConfig from a Standard config loader.
 //
 // Environment variable mappings:
 //   - SERVER_HOST -> host (default: localhost)
-//   - SERVER_PORT -> port (default: 8080)
+//   - SERVER_PORT -> port (default: 8080; 0 picks an ephemeral port)
+//   - SERVER_LISTEN -> listen (tcp://host:port, unix:///path or fd://, overrides host and port)
+//   - SERVER_SOCKET_MODE -> socket_mode (octal unix socket permissions, e.g. 0660)
 //   - SERVER_READ_TIMEOUT -> read_timeout (default: 15s)
+//   - SERVER_READ_HEADER_TIMEOUT -> read_header_timeout (default: 5s)
 //   - SERVER_WRITE_TIMEOUT -> write_timeout (default: 15s)
 //   - SERVER_IDLE_TIMEOUT -> idle_timeout (default: 60s)
+//   - SERVER_MAX_HEADER_BYTES -> max_header_bytes (default: 1048576)
+//   - SERVER_SHUTDOWN_TIMEOUT -> shutdown_timeout (default: 30s)
+//   - SERVER_TLS_CERT_FILE -> tls.cert_file
+//   - SERVER_TLS_KEY_FILE -> tls.key_file
+//   - SERVER_TLS_CLIENT_CA_FILE -> tls.client_ca_file
+//   - SERVER_TLS_MIN_VERSION -> tls.min_version (default: 1.2)
+//   - SERVER_TLS_CIPHER_SUITES -> tls.cipher_suites (comma-separated)
+//   - SERVER_TLS_CLIENT_AUTH -> tls.client_auth (default: none)
+//   - SERVER_CORS_ALLOWED_ORIGINS -> cors.allowed_origins (comma-separated, enables CORS)
+//   - SERVER_CORS_ALLOWED_METHODS -> cors.allowed_methods (default: GET, HEAD, POST, PUT, PATCH, DELETE)
+//   - SERVER_CORS_ALLOWED_HEADERS -> cors.allowed_headers (default: Accept, Authorization, Content-Type)
+//   - SERVER_CORS_EXPOSED_HEADERS -> cors.exposed_headers
+//   - SERVER_CORS_ALLOW_CREDENTIALS -> cors.allow_credentials (default: false)
+//   - SERVER_CORS_MAX_AGE -> cors.max_age
+//   - SERVER_TRUSTED_PROXIES -> trusted_proxies (comma-separated IPs or CIDRs)
+//   - SERVER_MAX_BODY_SIZE -> max_body_size (e.g. 10MB; default: unlimited)
 func ServerConfigFromViper(s *Standard) ServerConfig {
 	// Bind environment variables
-	_ = s.BindEnv("server.host", "SERVER_HOST")
-	_ = s.BindEnv("server.port", "SERVER_PORT")
-	_ = s.BindEnv("server.read_timeout", "SERVER_READ_TIMEOUT")
-	_ = s.BindEnv("server.write_timeout", "SERVER_WRITE_TIMEOUT")
-	_ = s.BindEnv("server.idle_timeout", "SERVER_IDLE_TIMEOUT")
+	bindServerEnv(s, "server.", "SERVER_")
 
-	config := ServerConfig{
-		Host:         s.GetString("server.host"),
-		Port:         s.GetInt("server.port"),
-		ReadTimeout:  s.viper.GetDuration("server.read_timeout"),
-		WriteTimeout: s.viper.GetDuration("server.write_timeout"),
-		IdleTimeout:  s.viper.GetDuration("server.idle_timeout"),
-	}
+	config := loadServerConfig(s, "server.", defaultServerPort)
 
 	// Apply defaults
 	config.setDefaults()
@@ -44,47 +78,222 @@ func ServerConfigFromViper(s *Standard) ServerConfig {
 	return config
 }
 
+// bindServerEnv binds the server keys under prefix to environment variables
+// starting with envPrefix.
+func bindServerEnv(s *Standard, prefix, envPrefix string) {
+	_ = s.BindEnv(prefix+"host", envPrefix+"HOST")
+	_ = s.BindEnv(prefix+"port", envPrefix+"PORT")
+	_ = s.BindEnv(prefix+"listen", envPrefix+"LISTEN")
+	_ = s.BindEnv(prefix+"socket_mode", envPrefix+"SOCKET_MODE")
+	_ = s.BindEnv(prefix+"read_timeout", envPrefix+"READ_TIMEOUT")
+	_ = s.BindEnv(prefix+"read_header_timeout", envPrefix+"READ_HEADER_TIMEOUT")
+	_ = s.BindEnv(prefix+"write_timeout", envPrefix+"WRITE_TIMEOUT")
+	_ = s.BindEnv(prefix+"idle_timeout", envPrefix+"IDLE_TIMEOUT")
+	_ = s.BindEnv(prefix+"max_header_bytes", envPrefix+"MAX_HEADER_BYTES")
+	_ = s.BindEnv(prefix+"shutdown_timeout", envPrefix+"SHUTDOWN_TIMEOUT")
+	_ = s.BindEnv(prefix+"tls.cert_file", envPrefix+"TLS_CERT_FILE")
+	_ = s.BindEnv(prefix+"tls.key_file", envPrefix+"TLS_KEY_FILE")
+	_ = s.BindEnv(prefix+"tls.client_ca_file", envPrefix+"TLS_CLIENT_CA_FILE")
+	_ = s.BindEnv(prefix+"tls.min_version", envPrefix+"TLS_MIN_VERSION")
+	_ = s.BindEnv(prefix+"tls.cipher_suites", envPrefix+"TLS_CIPHER_SUITES")
+	_ = s.BindEnv(prefix+"tls.client_auth", envPrefix+"TLS_CLIENT_AUTH")
+	_ = s.BindEnv(prefix+"cors.allowed_origins", envPrefix+"CORS_ALLOWED_ORIGINS")
+	_ = s.BindEnv(prefix+"cors.allowed_methods", envPrefix+"CORS_ALLOWED_METHODS")
+	_ = s.BindEnv(prefix+"cors.allowed_headers", envPrefix+"CORS_ALLOWED_HEADERS")
+	_ = s.BindEnv(prefix+"cors.exposed_headers", envPrefix+"CORS_EXPOSED_HEADERS")
+	_ = s.BindEnv(prefix+"cors.allow_credentials", envPrefix+"CORS_ALLOW_CREDENTIALS")
+	_ = s.BindEnv(prefix+"cors.max_age", envPrefix+"CORS_MAX_AGE")
+	_ = s.BindEnv(prefix+"trusted_proxies", envPrefix+"TRUSTED_PROXIES")
+	_ = s.BindEnv(prefix+"max_body_size", envPrefix+"MAX_BODY_SIZE")
+}
+
+// loadServerConfig reads the server keys under prefix, using defaultPort when
+// the port is unset
+func loadServerConfig(s *Standard, prefix string, defaultPort int) ServerConfig {
+	return ServerConfig{
+		Host:              s.GetString(prefix + "host"),
+		Port:              s.intOr(prefix+"port", defaultPort),
+		ListenAddr:        s.GetString(prefix + "listen"),
+		SocketMode:        s.GetString(prefix + "socket_mode"),
+		ReadTimeout:       s.viper.GetDuration(prefix + "read_timeout"),
+		ReadHeaderTimeout: s.viper.GetDuration(prefix + "read_header_timeout"),
+		WriteTimeout:      s.viper.GetDuration(prefix + "write_timeout"),
+		IdleTimeout:       s.viper.GetDuration(prefix + "idle_timeout"),
+		MaxHeaderBytes:    s.GetInt(prefix + "max_header_bytes"),
+		ShutdownTimeout:   s.viper.GetDuration(prefix + "shutdown_timeout"),
+		TLS:               loadServerTLSConfig(s, prefix+"tls."),
+		Policy:            loadHTTPPolicyConfig(s, prefix),
+		keyPrefix:         strings.TrimSuffix(prefix, "."),
+	}
+}
+
+// defaultServerPort is applied when server.port is unset. It is not applied in
+// setDefaults because an explicit port 0 requests an ephemeral port.
+const defaultServerPort = 8080
+
 // setDefaults sets default values for optional fields
 func (c *ServerConfig) setDefaults() {
 	if c.Host == "" {
 		c.Host = "localhost"
 	}
-	if c.Port == 0 {
-		c.Port = 8080
-	}
 	if c.ReadTimeout == 0 {
 		c.ReadTimeout = 15 * time.Second
 	}
+	if c.ReadHeaderTimeout == 0 {
+		c.ReadHeaderTimeout = 5 * time.Second
+	}
 	if c.WriteTimeout == 0 {
 		c.WriteTimeout = 15 * time.Second
 	}
 	if c.IdleTimeout == 0 {
 		c.IdleTimeout = 60 * time.Second
 	}
+	if c.MaxHeaderBytes == 0 {
+		c.MaxHeaderBytes = http.DefaultMaxHeaderBytes
+	}
+	if c.ShutdownTimeout == 0 {
+		c.ShutdownTimeout = 30 * time.Second
+	}
+	c.TLS.setDefaults()
+	c.Policy.setDefaults()
 }
 
 // Validate validates the server configuration
 func (c *ServerConfig) Validate() error {
-	if err := ValidateRequired("server.host", c.Host); err != nil {
+	if c.ListenAddr == "" {
+		if err := ValidateRequired(c.key("host"), c.Host); err != nil {
+			return err
+		}
+		// Port 0 asks the operating system for an ephemeral port
+		if err := ValidateRange(c.key("port"), c.Port, 0, 65535); err != nil {
+			return err
+		}
+	} else if err := c.validateListen(); err != nil {
+		return err
+	}
+	if err := ValidateDuration(c.key("read_timeout"), c.ReadTimeout); err != nil {
+		return err
+	}
+	if err := ValidateDuration(c.key("read_header_timeout"), c.ReadHeaderTimeout); err != nil {
 		return err
 	}
-	if err := ValidatePort("server.port", c.Port); err != nil {
+	if c.ReadTimeout > 0 && c.ReadHeaderTimeout > c.ReadTimeout {
+		return fmt.Errorf("%s (%v) must be less than or equal to read_timeout (%v)",
+			c.key("read_header_timeout"), c.ReadHeaderTimeout, c.ReadTimeout)
+	}
+	if err := ValidateDuration(c.key("write_timeout"), c.WriteTimeout); err != nil {
+		return err
+	}
+	if err := ValidateDuration(c.key("idle_timeout"), c.IdleTimeout); err != nil {
 		return err
 	}
-	if err := ValidateDuration("server.read_timeout", c.ReadTimeout); err != nil {
+	if c.MaxHeaderBytes < 0 {
+		return fmt.Errorf("%s must not be negative, got %d", c.key("max_header_bytes"), c.MaxHeaderBytes)
+	}
+	if err := ValidateDuration(c.key("shutdown_timeout"), c.ShutdownTimeout); err != nil {
 		return err
 	}
-	if err := ValidateDuration("server.write_timeout", c.WriteTimeout); err != nil {
+	if err := c.TLS.validate(c.key("tls")); err != nil {
 		return err
 	}
-	if err := ValidateDuration("server.idle_timeout", c.IdleTimeout); err != nil {
+	if err := c.Policy.validate(strings.TrimSuffix(c.key(""), ".")); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-// Address returns the server address in host:port format
+// key returns the fully qualified config key for field, used in validation
+// errors so that the public and admin servers can be told apart.
+func (c *ServerConfig) key(field string) string {
+	if c.keyPrefix == "" {
+		return "server." + field
+	}
+	return c.keyPrefix + "." + field
+}
+
+// Address returns the server address in host:port format, with IPv6 hosts
+// in brackets
 func (c *ServerConfig) Address() string {
-	return fmt.Sprintf("%s:%d", c.Host, c.Port)
+	return net.JoinHostPort(c.Host, strconv.Itoa(c.Port))
+}
+
+// NewHTTPServer returns an *http.Server for handler with the configured
+// address, timeouts and header limits.
+//
+// ReadHeaderTimeout is always set, which protects against Slowloris-style
+// attacks that a plain http.Server leaves open.
+func (c *ServerConfig) NewHTTPServer(handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              c.Address(),
+		Handler:           handler,
+		ReadTimeout:       c.ReadTimeout,
+		ReadHeaderTimeout: c.ReadHeaderTimeout,
+		WriteTimeout:      c.WriteTimeout,
+		IdleTimeout:       c.IdleTimeout,
+		MaxHeaderBytes:    c.MaxHeaderBytes,
+	}
+}
+
+// Run listens on the configured address (see Listen) and serves handler until
+// ctx is cancelled, then shuts down gracefully, waiting up to ShutdownTimeout
+// for in-flight requests to complete. When TLS is configured, Run serves HTTPS
+// using TLSConfig.
+//
+// Run returns nil after a clean shutdown, or the error that stopped the server.
+func (c *ServerConfig) Run(ctx context.Context, handler http.Handler) error {
+	listener, err := c.Listen(ctx)
+	if err != nil {
+		return err
+	}
+	if c.OnListen != nil {
+		c.OnListen(listener.Addr())
+	}
+	return c.Serve(ctx, listener, handler)
+}
+
+// Serve serves handler on listener as Run does, for callers that create the
+// listener themselves. Serve closes the listener when it returns.
+func (c *ServerConfig) Serve(ctx context.Context, listener net.Listener, handler http.Handler) error {
+	srv := c.NewHTTPServer(handler)
+	if c.TLS.Enabled() {
+		tlsConfig, err := c.TLSConfig()
+		if err != nil {
+			_ = listener.Close()
+			return err
+		}
+		srv.TLSConfig = tlsConfig
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		if srv.TLSConfig != nil {
+			serveErr <- srv.ServeTLS(listener, "", "")
+			return
+		}
+		serveErr <- srv.Serve(listener)
+	}()
+
+	select {
+	case err := <-serveErr:
+		return fmt.Errorf("server stopped: %w", err)
+	case <-ctx.Done():
+	}
+
+	shutdownCtx := context.WithoutCancel(ctx)
+	if c.ShutdownTimeout > 0 {
+		var cancel context.CancelFunc
+		shutdownCtx, cancel = context.WithTimeout(shutdownCtx, c.ShutdownTimeout)
+		defer cancel()
+	}
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		_ = srv.Close()
+		return fmt.Errorf("server shutdown: %w", err)
+	}
+	if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("server stopped: %w", err)
+	}
+
+	return nil
 }
@@ -0,0 +1,220 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Address schemes supported by ServerConfig.ListenAddr
+const (
+	listenSchemeTCP  = "tcp://"
+	listenSchemeUnix = "unix://"
+	listenSchemeFD   = "fd://"
+)
+
+// systemdListenFDsStart is the first file descriptor passed by systemd socket activation
+const systemdListenFDsStart = 3
+
+// validateListen validates ListenAddr and SocketMode
+func (c *ServerConfig) validateListen() error {
+	switch {
+	case strings.HasPrefix(c.ListenAddr, listenSchemeTCP):
+		_, port, err := net.SplitHostPort(strings.TrimPrefix(c.ListenAddr, listenSchemeTCP))
+		if err != nil {
+			return fmt.Errorf("%s must be tcp://host:port: %w", c.key("listen"), err)
+		}
+		if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+			return fmt.Errorf("%s port must be between 0 and 65535, got %q", c.key("listen"), port)
+		}
+	case strings.HasPrefix(c.ListenAddr, listenSchemeUnix):
+		if err := ValidateRequired(c.key("listen")+" socket path", strings.TrimPrefix(c.ListenAddr, listenSchemeUnix)); err != nil {
+			return err
+		}
+	case strings.HasPrefix(c.ListenAddr, listenSchemeFD):
+		// fd://, fd://<number> and fd://<name> are resolved at listen time
+	default:
+		return fmt.Errorf("%s must start with %s, %s or %s, got %q",
+			c.key("listen"), listenSchemeTCP, listenSchemeUnix, listenSchemeFD, c.ListenAddr)
+	}
+
+	if c.SocketMode != "" {
+		if _, err := parseSocketMode(c.SocketMode); err != nil {
+			return fmt.Errorf("%s %w", c.key("socket_mode"), err)
+		}
+	}
+
+	return nil
+}
+
+// parseSocketMode parses an octal permission string such as "0660"
+func parseSocketMode(mode string) (os.FileMode, error) {
+	perm, err := strconv.ParseUint(mode, 8, 32)
+	if err != nil || perm > 0o777 {
+		return 0, fmt.Errorf("must be octal permissions such as 0660, got %q", mode)
+	}
+	return os.FileMode(perm), nil
+}
+
+// Listen opens the listener described by ListenAddr:
+//
+//   - empty ListenAddr: TCP on Host:Port, where port 0 picks an ephemeral port
+//   - tcp://host:port: TCP on the given address
+//   - unix:///path/to.sock: a unix domain socket, with SocketMode permissions.
+//     A stale socket file left by a previous process is removed first.
+//   - fd://<number>: an inherited listening file descriptor
+//   - fd:// or fd://<name>: systemd socket activation via LISTEN_FDS, selecting
+//     the first socket or the one named in LISTEN_FDNAMES
+//
+// Listen does not modify the config. The address actually bound, such as the
+// port picked for port 0, is available from the listener's Addr; see
+// SetBoundAddress to record it in the config.
+func (c *ServerConfig) Listen(ctx context.Context) (net.Listener, error) {
+	var (
+		listener net.Listener
+		err      error
+	)
+
+	switch {
+	case c.ListenAddr == "":
+		listener, err = listenTCP(ctx, c.Address())
+	case strings.HasPrefix(c.ListenAddr, listenSchemeTCP):
+		listener, err = listenTCP(ctx, strings.TrimPrefix(c.ListenAddr, listenSchemeTCP))
+	case strings.HasPrefix(c.ListenAddr, listenSchemeUnix):
+		listener, err = c.listenUnix(ctx, strings.TrimPrefix(c.ListenAddr, listenSchemeUnix))
+	case strings.HasPrefix(c.ListenAddr, listenSchemeFD):
+		listener, err = listenFD(strings.TrimPrefix(c.ListenAddr, listenSchemeFD))
+	default:
+		return nil, c.validateListen()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return listener, nil
+}
+
+// SetBoundAddress records the address a listener returned by Listen is bound
+// to: for TCP it sets Port, so that Address reports the real port when port 0
+// was requested. Call it before the config is shared with other goroutines,
+// such as before starting Serve.
+func (c *ServerConfig) SetBoundAddress(addr net.Addr) {
+	if tcp, ok := addr.(*net.TCPAddr); ok {
+		c.Port = tcp.Port
+	}
+}
+
+// listenTCP listens on a TCP address
+func listenTCP(ctx context.Context, address string) (net.Listener, error) {
+	var lc net.ListenConfig
+	listener, err := lc.Listen(ctx, "tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", address, err)
+	}
+	return listener, nil
+}
+
+// listenUnix listens on a unix domain socket, removing a stale socket file
+// and applying SocketMode
+func (c *ServerConfig) listenUnix(ctx context.Context, path string) (net.Listener, error) {
+	if err := removeStaleSocket(path); err != nil {
+		return nil, err
+	}
+
+	var lc net.ListenConfig
+	listener, err := lc.Listen(ctx, "unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on unix socket %s: %w", path, err)
+	}
+
+	if c.SocketMode != "" {
+		mode, err := parseSocketMode(c.SocketMode)
+		if err != nil {
+			_ = listener.Close()
+			return nil, fmt.Errorf("%s %w", c.key("socket_mode"), err)
+		}
+		if err := os.Chmod(path, mode); err != nil {
+			_ = listener.Close()
+			return nil, fmt.Errorf("failed to set permissions on unix socket %s: %w", path, err)
+		}
+	}
+
+	return listener, nil
+}
+
+// removeStaleSocket removes a socket file at path that no process is
+// listening on. It refuses to remove regular files or live sockets.
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to inspect unix socket %s: %w", path, err)
+	}
+	if info.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("unix socket path %s exists and is not a socket", path)
+	}
+
+	conn, err := net.Dial("unix", path)
+	if err == nil {
+		_ = conn.Close()
+		return fmt.Errorf("unix socket %s is already in use", path)
+	}
+
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("failed to remove stale unix socket %s: %w", path, err)
+	}
+	return nil
+}
+
+// listenFD creates a listener from an inherited file descriptor, given either
+// as a number or as a systemd socket activation name (empty for the first)
+func listenFD(ref string) (net.Listener, error) {
+	fd, err := strconv.Atoi(ref)
+	if err != nil {
+		if fd, err = systemdListenFD(ref); err != nil {
+			return nil, err
+		}
+	}
+
+	f := os.NewFile(uintptr(fd), "fd://"+strconv.Itoa(fd))
+	if f == nil {
+		return nil, fmt.Errorf("file descriptor %d is not valid", fd)
+	}
+	defer f.Close()
+
+	listener, err := net.FileListener(f)
+	if err != nil {
+		return nil, fmt.Errorf("file descriptor %d is not a listening socket: %w", fd, err)
+	}
+	return listener, nil
+}
+
+// systemdListenFD finds the file descriptor for name in the systemd socket
+// activation environment (LISTEN_PID, LISTEN_FDS and LISTEN_FDNAMES)
+func systemdListenFD(name string) (int, error) {
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return 0, errors.New("no systemd socket activation: LISTEN_PID does not match this process")
+	}
+	count, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || count < 1 {
+		return 0, errors.New("no systemd socket activation: LISTEN_FDS is not set")
+	}
+
+	if name == "" {
+		return systemdListenFDsStart, nil
+	}
+
+	names := strings.Split(os.Getenv("LISTEN_FDNAMES"), ":")
+	for i := 0; i < count && i < len(names); i++ {
+		if names[i] == name {
+			return systemdListenFDsStart + i, nil
+		}
+	}
+	return 0, fmt.Errorf("no systemd socket named %q in LISTEN_FDNAMES", name)
+}
@@ -0,0 +1,257 @@
+package config_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	config "github.com/JohnPlummer/jp-go-config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServerConfig_ListenFromViper(t *testing.T) {
+	os.Setenv("SERVER_PORT", "0")
+	os.Setenv("SERVER_LISTEN", "unix:///run/app/app.sock")
+	os.Setenv("SERVER_SOCKET_MODE", "0660")
+	defer func() {
+		os.Unsetenv("SERVER_PORT")
+		os.Unsetenv("SERVER_LISTEN")
+		os.Unsetenv("SERVER_SOCKET_MODE")
+	}()
+
+	std, err := config.NewStandard()
+	require.NoError(t, err)
+
+	cfg := config.ServerConfigFromViper(std)
+
+	assert.Equal(t, 0, cfg.Port)
+	assert.Equal(t, "unix:///run/app/app.sock", cfg.ListenAddr)
+	assert.Equal(t, "0660", cfg.SocketMode)
+}
+
+func TestServerConfig_ListenValidate(t *testing.T) {
+	t.Run("port 0 passes", func(t *testing.T) {
+		cfg := config.ServerConfig{Host: "127.0.0.1", Port: 0}
+		require.NoError(t, cfg.Validate())
+	})
+
+	t.Run("listen addresses pass", func(t *testing.T) {
+		for _, addr := range []string{"tcp://0.0.0.0:8080", "tcp://[::1]:0", "unix:///run/app.sock", "fd://", "fd://3", "fd://http"} {
+			cfg := config.ServerConfig{ListenAddr: addr}
+			require.NoError(t, cfg.Validate(), addr)
+		}
+	})
+
+	t.Run("unknown scheme fails", func(t *testing.T) {
+		cfg := config.ServerConfig{ListenAddr: "udp://0.0.0.0:53"}
+
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "server.listen must start with")
+	})
+
+	t.Run("invalid tcp address fails", func(t *testing.T) {
+		cfg := config.ServerConfig{ListenAddr: "tcp://localhost"}
+
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "server.listen must be tcp://host:port")
+	})
+
+	t.Run("invalid socket mode fails", func(t *testing.T) {
+		cfg := config.ServerConfig{ListenAddr: "unix:///run/app.sock", SocketMode: "rw-rw----"}
+
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "server.socket_mode must be octal permissions")
+	})
+}
+
+func TestServerConfig_AddressIPv6(t *testing.T) {
+	cfg := config.ServerConfig{Host: "::1", Port: 8080}
+	assert.Equal(t, "[::1]:8080", cfg.Address())
+}
+
+func TestServerConfig_Listen(t *testing.T) {
+	t.Run("reports ephemeral tcp port through the listener", func(t *testing.T) {
+		cfg := config.ServerConfig{Host: "127.0.0.1", Port: 0}
+
+		listener, err := cfg.Listen(context.Background())
+		require.NoError(t, err)
+		defer listener.Close()
+
+		assert.NotZero(t, listener.Addr().(*net.TCPAddr).Port)
+		assert.Equal(t, 0, cfg.Port, "Listen must not modify the config")
+
+		cfg.SetBoundAddress(listener.Addr())
+		assert.Equal(t, listener.Addr().String(), cfg.Address())
+	})
+
+	t.Run("listens on tcp scheme", func(t *testing.T) {
+		cfg := config.ServerConfig{ListenAddr: "tcp://127.0.0.1:0"}
+
+		listener, err := cfg.Listen(context.Background())
+		require.NoError(t, err)
+		defer listener.Close()
+
+		assert.NotZero(t, listener.Addr().(*net.TCPAddr).Port)
+		assert.Equal(t, 0, cfg.Port)
+	})
+
+	t.Run("listens on unix socket with permissions", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "app.sock")
+		cfg := config.ServerConfig{ListenAddr: "unix://" + path, SocketMode: "0600"}
+
+		listener, err := cfg.Listen(context.Background())
+		require.NoError(t, err)
+		defer listener.Close()
+
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+		assert.NotZero(t, info.Mode()&os.ModeSocket)
+	})
+
+	t.Run("removes stale unix socket", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "app.sock")
+		stale, err := net.ListenUnix("unix", &net.UnixAddr{Name: path, Net: "unix"})
+		require.NoError(t, err)
+		stale.SetUnlinkOnClose(false)
+		require.NoError(t, stale.Close())
+		require.FileExists(t, path)
+
+		cfg := config.ServerConfig{ListenAddr: "unix://" + path}
+
+		listener, err := cfg.Listen(context.Background())
+		require.NoError(t, err)
+		defer listener.Close()
+	})
+
+	t.Run("refuses unix socket in use", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "app.sock")
+		live, err := net.Listen("unix", path)
+		require.NoError(t, err)
+		defer live.Close()
+
+		cfg := config.ServerConfig{ListenAddr: "unix://" + path}
+
+		_, err = cfg.Listen(context.Background())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "is already in use")
+	})
+
+	t.Run("refuses to replace regular file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "app.sock")
+		require.NoError(t, os.WriteFile(path, []byte("data"), 0o644))
+
+		cfg := config.ServerConfig{ListenAddr: "unix://" + path}
+
+		_, err := cfg.Listen(context.Background())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "exists and is not a socket")
+	})
+}
+
+// inheritedListener returns a TCP listener and the number of a duplicate file
+// descriptor for it, as a parent process would pass one down
+func inheritedListener(t *testing.T) (net.Listener, int) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	f, err := listener.(*net.TCPListener).File()
+	require.NoError(t, err)
+	t.Cleanup(func() { f.Close() })
+
+	return listener, int(f.Fd())
+}
+
+// assertServes checks that an HTTP server on listener answers requests
+func assertServes(t *testing.T, cfg config.ServerConfig, listener net.Listener, url string) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- cfg.Serve(ctx, listener, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = io.WriteString(w, "ok")
+		}))
+	}()
+
+	resp, err := testClient.Get(url)
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "ok", string(body))
+
+	cancel()
+	require.NoError(t, <-done)
+}
+
+func TestServerConfig_ListenFD(t *testing.T) {
+	t.Run("uses numbered file descriptor", func(t *testing.T) {
+		original, fd := inheritedListener(t)
+		cfg := config.ServerConfig{ListenAddr: "fd://" + strconv.Itoa(fd), ShutdownTimeout: 5 * time.Second}
+
+		listener, err := cfg.Listen(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, original.Addr().String(), listener.Addr().String())
+
+		assertServes(t, cfg, listener, "http://"+original.Addr().String())
+	})
+
+	t.Run("uses systemd socket activation by name", func(t *testing.T) {
+		original, fd := inheritedListener(t)
+		require.GreaterOrEqual(t, fd, 3)
+
+		// Describe descriptors 3..fd, naming the last one "http"
+		names := make([]string, fd-3+1)
+		for i := range names {
+			names[i] = "unused"
+		}
+		names[len(names)-1] = "http"
+
+		t.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()))
+		t.Setenv("LISTEN_FDS", strconv.Itoa(len(names)))
+		t.Setenv("LISTEN_FDNAMES", strings.Join(names, ":"))
+
+		cfg := config.ServerConfig{ListenAddr: "fd://http", ShutdownTimeout: 5 * time.Second}
+
+		listener, err := cfg.Listen(context.Background())
+		require.NoError(t, err)
+
+		assertServes(t, cfg, listener, "http://"+original.Addr().String())
+	})
+
+	t.Run("fails without socket activation", func(t *testing.T) {
+		t.Setenv("LISTEN_PID", "1")
+		t.Setenv("LISTEN_FDS", "1")
+
+		cfg := config.ServerConfig{ListenAddr: "fd://"}
+
+		_, err := cfg.Listen(context.Background())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "LISTEN_PID does not match this process")
+	})
+
+	t.Run("fails for unknown socket name", func(t *testing.T) {
+		t.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()))
+		t.Setenv("LISTEN_FDS", "1")
+		t.Setenv("LISTEN_FDNAMES", "http")
+
+		cfg := config.ServerConfig{ListenAddr: "fd://admin"}
+
+		_, err := cfg.Listen(context.Background())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `no systemd socket named "admin"`)
+	})
+}
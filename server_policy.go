@@ -0,0 +1,377 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HTTPPolicyConfig holds request policy settings for ServerConfig: CORS,
+// trusted proxies for X-Forwarded-For, and the maximum request body size.
+// Every setting is off by default; Middleware applies the enabled ones.
+type HTTPPolicyConfig struct {
+	CORS CORSConfig `mapstructure:"cors"`
+
+	// TrustedProxies lists proxy IPs or CIDRs whose X-Forwarded-For is honoured
+	TrustedProxies []string `mapstructure:"trusted_proxies"`
+
+	// MaxBodySize limits request bodies, e.g. "10MB" (empty: unlimited)
+	MaxBodySize string `mapstructure:"max_body_size"`
+}
+
+// CORSConfig holds cross-origin resource sharing settings. CORS is enabled
+// when AllowedOrigins is set.
+type CORSConfig struct {
+	// AllowedOrigins lists origins such as https://app.example.com. "*" allows
+	// any origin and https://*.example.com allows any subdomain.
+	AllowedOrigins   []string      `mapstructure:"allowed_origins"`
+	AllowedMethods   []string      `mapstructure:"allowed_methods"`
+	AllowedHeaders   []string      `mapstructure:"allowed_headers"`
+	ExposedHeaders   []string      `mapstructure:"exposed_headers"`
+	AllowCredentials bool          `mapstructure:"allow_credentials"`
+	MaxAge           time.Duration `mapstructure:"max_age"`
+}
+
+// byteSizeUnits maps size suffixes to multipliers. KB, MB and GB are binary
+// multiples, matching KiB, MiB and GiB.
+var byteSizeUnits = map[string]int64{
+	"":    1,
+	"B":   1,
+	"K":   1 << 10,
+	"KB":  1 << 10,
+	"KIB": 1 << 10,
+	"M":   1 << 20,
+	"MB":  1 << 20,
+	"MIB": 1 << 20,
+	"G":   1 << 30,
+	"GB":  1 << 30,
+	"GIB": 1 << 30,
+}
+
+// loadHTTPPolicyConfig reads the policy keys under prefix
+func loadHTTPPolicyConfig(s *Standard, prefix string) HTTPPolicyConfig {
+	return HTTPPolicyConfig{
+		CORS: CORSConfig{
+			AllowedOrigins:   s.stringList(prefix + "cors.allowed_origins"),
+			AllowedMethods:   s.stringList(prefix + "cors.allowed_methods"),
+			AllowedHeaders:   s.stringList(prefix + "cors.allowed_headers"),
+			ExposedHeaders:   s.stringList(prefix + "cors.exposed_headers"),
+			AllowCredentials: s.GetBool(prefix + "cors.allow_credentials"),
+			MaxAge:           s.viper.GetDuration(prefix + "cors.max_age"),
+		},
+		TrustedProxies: s.stringList(prefix + "trusted_proxies"),
+		MaxBodySize:    s.GetString(prefix + "max_body_size"),
+	}
+}
+
+// setDefaults sets default values for optional fields
+func (c *HTTPPolicyConfig) setDefaults() {
+	if len(c.CORS.AllowedMethods) == 0 {
+		c.CORS.AllowedMethods = []string{
+			http.MethodGet, http.MethodHead, http.MethodPost,
+			http.MethodPut, http.MethodPatch, http.MethodDelete,
+		}
+	}
+	if len(c.CORS.AllowedHeaders) == 0 {
+		c.CORS.AllowedHeaders = []string{"Accept", "Authorization", "Content-Type"}
+	}
+}
+
+// validate validates the policy settings, prefixing errors with the server key
+func (c *HTTPPolicyConfig) validate(prefix string) error {
+	for _, origin := range c.CORS.AllowedOrigins {
+		if err := validateOriginPattern(origin); err != nil {
+			return fmt.Errorf("%s.cors.allowed_origins: %w", prefix, err)
+		}
+		if origin == "*" && c.CORS.AllowCredentials {
+			return fmt.Errorf("%s.cors.allowed_origins must not contain * when allow_credentials is true", prefix)
+		}
+	}
+	if c.CORS.MaxAge < 0 {
+		return fmt.Errorf("%s.cors.max_age must not be negative, got %v", prefix, c.CORS.MaxAge)
+	}
+	if _, err := parseTrustedProxies(c.TrustedProxies); err != nil {
+		return fmt.Errorf("%s.trusted_proxies: %w", prefix, err)
+	}
+	if _, err := c.MaxBodyBytes(); err != nil {
+		return fmt.Errorf("%s.max_body_size: %w", prefix, err)
+	}
+	return nil
+}
+
+// MaxBodyBytes returns MaxBodySize in bytes, or 0 when no limit is set
+func (c *HTTPPolicyConfig) MaxBodyBytes() (int64, error) {
+	if c.MaxBodySize == "" {
+		return 0, nil
+	}
+	return ParseByteSize(c.MaxBodySize)
+}
+
+// ParseByteSize parses a size such as "512", "64KB", "10MB" or "1GiB" into
+// bytes. Units are case-insensitive and binary, so 1KB is 1024 bytes.
+func ParseByteSize(size string) (int64, error) {
+	trimmed := strings.TrimSpace(size)
+	split := strings.IndexFunc(trimmed, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	number, unit := trimmed, ""
+	if split >= 0 {
+		number, unit = trimmed[:split], strings.TrimSpace(trimmed[split:])
+	}
+
+	multiplier, ok := byteSizeUnits[strings.ToUpper(unit)]
+	if !ok || number == "" {
+		return 0, fmt.Errorf("invalid byte size %q, expected a number with an optional B, KB, MB or GB unit", size)
+	}
+	value, err := strconv.ParseFloat(number, 64)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("invalid byte size %q, expected a number with an optional B, KB, MB or GB unit", size)
+	}
+	if value >= float64(math.MaxInt64/multiplier) {
+		return 0, fmt.Errorf("byte size %q is too large", size)
+	}
+	return int64(value * float64(multiplier)), nil
+}
+
+// validateOriginPattern validates an allowed origin: "*", or scheme://host
+// with an optional port and an optional leading "*." wildcard label
+func validateOriginPattern(origin string) error {
+	if origin == "*" {
+		return nil
+	}
+	u, err := url.Parse(strings.Replace(origin, "://*.", "://wildcard.", 1))
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("invalid origin %q, expected scheme://host[:port]", origin)
+	}
+	if (u.Path != "" && u.Path != "/") || u.RawQuery != "" || u.Fragment != "" || u.User != nil {
+		return fmt.Errorf("origin %q must not contain a path, query or credentials", origin)
+	}
+	if strings.Contains(u.Host, "*") {
+		return fmt.Errorf("origin %q may only use * as the leading subdomain label", origin)
+	}
+	return nil
+}
+
+// originAllowed reports whether origin matches one of patterns
+func originAllowed(patterns []string, origin string) bool {
+	origin = strings.ToLower(origin)
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(strings.TrimSuffix(pattern, "/"))
+		if pattern == "*" || pattern == origin {
+			return true
+		}
+		if scheme, host, ok := strings.Cut(pattern, "://*."); ok {
+			if strings.HasPrefix(origin, scheme+"://") && strings.HasSuffix(origin, "."+host) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// parseTrustedProxies parses IPs and CIDRs into prefixes
+func parseTrustedProxies(proxies []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(proxies))
+	for _, proxy := range proxies {
+		if strings.Contains(proxy, "/") {
+			prefix, err := netip.ParsePrefix(proxy)
+			if err != nil {
+				return nil, fmt.Errorf("invalid CIDR %q: %w", proxy, err)
+			}
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid IP %q: %w", proxy, err)
+		}
+		prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+	}
+	return prefixes, nil
+}
+
+// Middleware returns middleware applying every enabled policy: trusted proxy
+// resolution, then CORS, then the body size limit, so that rejected bodies
+// still carry CORS headers.
+func (c *HTTPPolicyConfig) Middleware() (func(http.Handler) http.Handler, error) {
+	proxies, err := c.TrustedProxiesMiddleware()
+	if err != nil {
+		return nil, err
+	}
+	limit, err := c.MaxBodyMiddleware()
+	if err != nil {
+		return nil, err
+	}
+	cors, err := c.CORSMiddleware()
+	if err != nil {
+		return nil, err
+	}
+	return func(next http.Handler) http.Handler {
+		return proxies(cors(limit(next)))
+	}, nil
+}
+
+// CORSMiddleware returns middleware that answers preflight requests and adds
+// CORS headers for allowed origins. Preflight requests from other origins get
+// 403 Forbidden. When AllowedOrigins is empty the middleware does nothing.
+func (c *HTTPPolicyConfig) CORSMiddleware() (func(http.Handler) http.Handler, error) {
+	for _, origin := range c.CORS.AllowedOrigins {
+		if err := validateOriginPattern(origin); err != nil {
+			return nil, err
+		}
+	}
+	if len(c.CORS.AllowedOrigins) == 0 {
+		return func(next http.Handler) http.Handler { return next }, nil
+	}
+
+	cors := c.CORS
+	anyOrigin := !cors.AllowCredentials && len(cors.AllowedOrigins) == 1 && cors.AllowedOrigins[0] == "*"
+	methods := strings.Join(cors.AllowedMethods, ", ")
+	headers := strings.Join(cors.AllowedHeaders, ", ")
+	exposed := strings.Join(cors.ExposedHeaders, ", ")
+	maxAge := strconv.Itoa(int(cors.MaxAge / time.Second))
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			if origin == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+			h := w.Header()
+			h.Add("Vary", "Origin")
+			if !originAllowed(cors.AllowedOrigins, origin) {
+				if preflight {
+					w.WriteHeader(http.StatusForbidden)
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if anyOrigin {
+				h.Set("Access-Control-Allow-Origin", "*")
+			} else {
+				h.Set("Access-Control-Allow-Origin", origin)
+			}
+			if cors.AllowCredentials {
+				h.Set("Access-Control-Allow-Credentials", "true")
+			}
+
+			if !preflight {
+				if exposed != "" {
+					h.Set("Access-Control-Expose-Headers", exposed)
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			h.Add("Vary", "Access-Control-Request-Method")
+			h.Add("Vary", "Access-Control-Request-Headers")
+			h.Set("Access-Control-Allow-Methods", methods)
+			h.Set("Access-Control-Allow-Headers", headers)
+			if cors.MaxAge > 0 {
+				h.Set("Access-Control-Max-Age", maxAge)
+			}
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}, nil
+}
+
+// TrustedProxiesMiddleware returns middleware that replaces r.RemoteAddr with
+// the client IP from X-Forwarded-For when the request comes from a trusted
+// proxy. The header is read right to left, skipping trusted proxies, so
+// clients cannot spoof their address by sending the header themselves. When
+// TrustedProxies is empty the middleware does nothing.
+func (c *HTTPPolicyConfig) TrustedProxiesMiddleware() (func(http.Handler) http.Handler, error) {
+	trusted, err := parseTrustedProxies(c.TrustedProxies)
+	if err != nil {
+		return nil, err
+	}
+	if len(trusted) == 0 {
+		return func(next http.Handler) http.Handler { return next }, nil
+	}
+
+	isTrusted := func(addr netip.Addr) bool {
+		addr = addr.Unmap()
+		for _, prefix := range trusted {
+			if prefix.Contains(addr) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if client, ok := forwardedClient(r, isTrusted); ok {
+				r = r.Clone(r.Context())
+				r.RemoteAddr = net.JoinHostPort(client.String(), "0")
+			}
+			next.ServeHTTP(w, r)
+		})
+	}, nil
+}
+
+// forwardedClient returns the first untrusted address in X-Forwarded-For,
+// reading right to left, when the immediate peer is trusted
+func forwardedClient(r *http.Request, isTrusted func(netip.Addr) bool) (netip.Addr, bool) {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	peer, err := netip.ParseAddr(host)
+	if err != nil || !isTrusted(peer) {
+		return netip.Addr{}, false
+	}
+
+	var hops []string
+	for _, value := range r.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(value, ",")...)
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			return netip.Addr{}, false
+		}
+		if !isTrusted(addr) {
+			return addr.Unmap(), true
+		}
+	}
+	return netip.Addr{}, false
+}
+
+// MaxBodyMiddleware returns middleware that limits request bodies to
+// MaxBodySize. Requests declaring a larger Content-Length get 413 Request
+// Entity Too Large; other oversized bodies fail when read with an
+// *http.MaxBytesError. When MaxBodySize is empty the middleware does nothing.
+func (c *HTTPPolicyConfig) MaxBodyMiddleware() (func(http.Handler) http.Handler, error) {
+	limit, err := c.MaxBodyBytes()
+	if err != nil {
+		return nil, err
+	}
+	if limit == 0 {
+		return func(next http.Handler) http.Handler { return next }, nil
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > limit {
+				http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+				return
+			}
+			if r.Body != nil {
+				r.Body = http.MaxBytesReader(w, r.Body, limit)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}, nil
+}
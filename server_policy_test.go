@@ -0,0 +1,319 @@
+package config_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	config "github.com/JohnPlummer/jp-go-config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServerConfigFromViper_Policy(t *testing.T) {
+	t.Run("is disabled by default", func(t *testing.T) {
+		std, err := config.NewStandard()
+		require.NoError(t, err)
+
+		cfg := config.ServerConfigFromViper(std)
+
+		assert.Empty(t, cfg.Policy.CORS.AllowedOrigins)
+		assert.Equal(t, []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE"}, cfg.Policy.CORS.AllowedMethods)
+		assert.Empty(t, cfg.Policy.TrustedProxies)
+		assert.Empty(t, cfg.Policy.MaxBodySize)
+	})
+
+	t.Run("loads from environment variables", func(t *testing.T) {
+		os.Setenv("SERVER_CORS_ALLOWED_ORIGINS", "https://app.example.com, https://*.example.org")
+		os.Setenv("SERVER_CORS_ALLOW_CREDENTIALS", "true")
+		os.Setenv("SERVER_CORS_MAX_AGE", "10m")
+		os.Setenv("SERVER_TRUSTED_PROXIES", "10.0.0.0/8,192.168.1.1")
+		os.Setenv("SERVER_MAX_BODY_SIZE", "10MB")
+		defer func() {
+			os.Unsetenv("SERVER_CORS_ALLOWED_ORIGINS")
+			os.Unsetenv("SERVER_CORS_ALLOW_CREDENTIALS")
+			os.Unsetenv("SERVER_CORS_MAX_AGE")
+			os.Unsetenv("SERVER_TRUSTED_PROXIES")
+			os.Unsetenv("SERVER_MAX_BODY_SIZE")
+		}()
+
+		std, err := config.NewStandard()
+		require.NoError(t, err)
+
+		cfg := config.ServerConfigFromViper(std)
+
+		assert.Equal(t, []string{"https://app.example.com", "https://*.example.org"}, cfg.Policy.CORS.AllowedOrigins)
+		assert.True(t, cfg.Policy.CORS.AllowCredentials)
+		assert.Equal(t, 10*time.Minute, cfg.Policy.CORS.MaxAge)
+		assert.Equal(t, []string{"10.0.0.0/8", "192.168.1.1"}, cfg.Policy.TrustedProxies)
+		require.NoError(t, cfg.Validate())
+
+		limit, err := cfg.Policy.MaxBodyBytes()
+		require.NoError(t, err)
+		assert.Equal(t, int64(10<<20), limit)
+	})
+}
+
+func TestServerConfig_Validate_Policy(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  config.HTTPPolicyConfig
+		wantErr string
+	}{
+		{
+			name:    "origin with path fails",
+			policy:  config.HTTPPolicyConfig{CORS: config.CORSConfig{AllowedOrigins: []string{"https://example.com/app"}}},
+			wantErr: "server.cors.allowed_origins",
+		},
+		{
+			name:    "origin without scheme fails",
+			policy:  config.HTTPPolicyConfig{CORS: config.CORSConfig{AllowedOrigins: []string{"example.com"}}},
+			wantErr: "server.cors.allowed_origins",
+		},
+		{
+			name:    "wildcard inside host fails",
+			policy:  config.HTTPPolicyConfig{CORS: config.CORSConfig{AllowedOrigins: []string{"https://api.*.example.com"}}},
+			wantErr: "leading subdomain",
+		},
+		{
+			name: "any origin with credentials fails",
+			policy: config.HTTPPolicyConfig{CORS: config.CORSConfig{
+				AllowedOrigins:   []string{"*"},
+				AllowCredentials: true,
+			}},
+			wantErr: "allow_credentials",
+		},
+		{
+			name:    "invalid CIDR fails",
+			policy:  config.HTTPPolicyConfig{TrustedProxies: []string{"10.0.0.0/33"}},
+			wantErr: "server.trusted_proxies",
+		},
+		{
+			name:    "invalid IP fails",
+			policy:  config.HTTPPolicyConfig{TrustedProxies: []string{"proxy.internal"}},
+			wantErr: "server.trusted_proxies",
+		},
+		{
+			name:    "invalid body size fails",
+			policy:  config.HTTPPolicyConfig{MaxBodySize: "10 parsecs"},
+			wantErr: "server.max_body_size",
+		},
+		{
+			name: "valid policy passes",
+			policy: config.HTTPPolicyConfig{
+				CORS:           config.CORSConfig{AllowedOrigins: []string{"*"}},
+				TrustedProxies: []string{"10.0.0.0/8", "::1"},
+				MaxBodySize:    "1.5MB",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.ServerConfig{
+				Host:         "localhost",
+				Port:         8080,
+				ReadTimeout:  15 * time.Second,
+				WriteTimeout: 15 * time.Second,
+				IdleTimeout:  60 * time.Second,
+				Policy:       tt.policy,
+			}
+
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestParseByteSize(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    int64
+		wantErr bool
+	}{
+		{input: "512", want: 512},
+		{input: "512B", want: 512},
+		{input: "64KB", want: 64 << 10},
+		{input: "10MB", want: 10 << 20},
+		{input: "10 mb", want: 10 << 20},
+		{input: "1GiB", want: 1 << 30},
+		{input: "1.5MB", want: 3 << 19},
+		{input: "", wantErr: true},
+		{input: "MB", wantErr: true},
+		{input: "10TB", wantErr: true},
+		{input: "-1MB", wantErr: true},
+		{input: "9999999999GB", wantErr: true},
+		{input: "9223372036854775807", wantErr: true},
+		{input: "8GB", want: 8 << 30},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := config.ParseByteSize(tt.input)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestHTTPPolicyConfig_CORSMiddleware(t *testing.T) {
+	policy := config.HTTPPolicyConfig{CORS: config.CORSConfig{
+		AllowedOrigins:   []string{"https://app.example.com", "https://*.example.org"},
+		AllowedMethods:   []string{"GET", "POST"},
+		AllowedHeaders:   []string{"Content-Type"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	}}
+	mw, err := policy.CORSMiddleware()
+	require.NoError(t, err)
+	handler := mw(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+
+	t.Run("adds headers for allowed origin", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Origin", "https://app.example.com")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusTeapot, rec.Code)
+		assert.Equal(t, "https://app.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+		assert.Equal(t, "true", rec.Header().Get("Access-Control-Allow-Credentials"))
+	})
+
+	t.Run("matches wildcard subdomains", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Origin", "https://api.example.org")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		assert.Equal(t, "https://api.example.org", rec.Header().Get("Access-Control-Allow-Origin"))
+	})
+
+	t.Run("omits headers for other origins", func(t *testing.T) {
+		for _, origin := range []string{"https://evil.example.com", "https://example.org", "http://api.example.org"} {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Origin", origin)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusTeapot, rec.Code, origin)
+			assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"), origin)
+		}
+	})
+
+	t.Run("answers preflight requests", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodOptions, "/", nil)
+		req.Header.Set("Origin", "https://app.example.com")
+		req.Header.Set("Access-Control-Request-Method", "POST")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Equal(t, "GET, POST", rec.Header().Get("Access-Control-Allow-Methods"))
+		assert.Equal(t, "Content-Type", rec.Header().Get("Access-Control-Allow-Headers"))
+		assert.Equal(t, "600", rec.Header().Get("Access-Control-Max-Age"))
+	})
+
+	t.Run("rejects preflight from other origins", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodOptions, "/", nil)
+		req.Header.Set("Origin", "https://evil.example.com")
+		req.Header.Set("Access-Control-Request-Method", "POST")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+}
+
+func TestHTTPPolicyConfig_TrustedProxiesMiddleware(t *testing.T) {
+	policy := config.HTTPPolicyConfig{TrustedProxies: []string{"10.0.0.0/8"}}
+	mw, err := policy.TrustedProxiesMiddleware()
+	require.NoError(t, err)
+
+	var remoteAddr string
+	handler := mw(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		remoteAddr = r.RemoteAddr
+	}))
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  string
+		want       string
+	}{
+		{name: "uses client from trusted proxy", remoteAddr: "10.0.0.1:1234", forwarded: "203.0.113.7", want: "203.0.113.7:0"},
+		{name: "skips trusted hops", remoteAddr: "10.0.0.1:1234", forwarded: "203.0.113.7, 10.1.1.1", want: "203.0.113.7:0"},
+		{name: "ignores spoofed left entries", remoteAddr: "10.0.0.1:1234", forwarded: "1.1.1.1, 203.0.113.7", want: "203.0.113.7:0"},
+		{name: "ignores header from untrusted peer", remoteAddr: "198.51.100.1:1234", forwarded: "203.0.113.7", want: "198.51.100.1:1234"},
+		{name: "ignores malformed header", remoteAddr: "10.0.0.1:1234", forwarded: "not-an-ip", want: "10.0.0.1:1234"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			req.Header.Set("X-Forwarded-For", tt.forwarded)
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			assert.Equal(t, tt.want, remoteAddr)
+		})
+	}
+}
+
+func TestHTTPPolicyConfig_MaxBodyMiddleware(t *testing.T) {
+	policy := config.HTTPPolicyConfig{MaxBodySize: "1KB"}
+	mw, err := policy.Middleware()
+	require.NoError(t, err)
+
+	handler := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := io.ReadAll(r.Body); err != nil {
+			var maxErr *http.MaxBytesError
+			if errors.As(err, &maxErr) {
+				w.WriteHeader(http.StatusRequestEntityTooLarge)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	t.Run("allows small bodies", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader("hello")))
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("rejects large declared content length", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(strings.Repeat("x", 2048))))
+		assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	})
+
+	t.Run("limits bodies without content length", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", io.NopCloser(strings.NewReader(strings.Repeat("x", 2048))))
+		req.ContentLength = -1
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	})
+
+	t.Run("invalid size fails to build", func(t *testing.T) {
+		bad := config.HTTPPolicyConfig{MaxBodySize: "lots"}
+		_, err := bad.Middleware()
+		require.Error(t, err)
+	})
+}
@@ -1,6 +1,10 @@
 package config_test
 
 import (
+	"context"
+	"io"
+	"net"
+	"net/http"
 	"os"
 	"testing"
 	"time"
@@ -20,8 +24,11 @@ func TestServerConfigFromViper(t *testing.T) {
 		assert.Equal(t, "localhost", cfg.Host)
 		assert.Equal(t, 8080, cfg.Port)
 		assert.Equal(t, 15*time.Second, cfg.ReadTimeout)
+		assert.Equal(t, 5*time.Second, cfg.ReadHeaderTimeout)
 		assert.Equal(t, 15*time.Second, cfg.WriteTimeout)
 		assert.Equal(t, 60*time.Second, cfg.IdleTimeout)
+		assert.Equal(t, 1<<20, cfg.MaxHeaderBytes)
+		assert.Equal(t, 30*time.Second, cfg.ShutdownTimeout)
 	})
 
 	t.Run("loads from environment variables", func(t *testing.T) {
@@ -30,7 +37,13 @@ func TestServerConfigFromViper(t *testing.T) {
 		os.Setenv("SERVER_READ_TIMEOUT", "30s")
 		os.Setenv("SERVER_WRITE_TIMEOUT", "30s")
 		os.Setenv("SERVER_IDLE_TIMEOUT", "120s")
+		os.Setenv("SERVER_READ_HEADER_TIMEOUT", "2s")
+		os.Setenv("SERVER_MAX_HEADER_BYTES", "65536")
+		os.Setenv("SERVER_SHUTDOWN_TIMEOUT", "10s")
 		defer func() {
+			os.Unsetenv("SERVER_READ_HEADER_TIMEOUT")
+			os.Unsetenv("SERVER_MAX_HEADER_BYTES")
+			os.Unsetenv("SERVER_SHUTDOWN_TIMEOUT")
 			os.Unsetenv("SERVER_HOST")
 			os.Unsetenv("SERVER_PORT")
 			os.Unsetenv("SERVER_READ_TIMEOUT")
@@ -48,6 +61,9 @@ func TestServerConfigFromViper(t *testing.T) {
 		assert.Equal(t, 30*time.Second, cfg.ReadTimeout)
 		assert.Equal(t, 30*time.Second, cfg.WriteTimeout)
 		assert.Equal(t, 120*time.Second, cfg.IdleTimeout)
+		assert.Equal(t, 2*time.Second, cfg.ReadHeaderTimeout)
+		assert.Equal(t, 65536, cfg.MaxHeaderBytes)
+		assert.Equal(t, 10*time.Second, cfg.ShutdownTimeout)
 	})
 }
 
@@ -80,7 +96,7 @@ func TestServerConfig_Validate(t *testing.T) {
 	t.Run("invalid port fails", func(t *testing.T) {
 		cfg := config.ServerConfig{
 			Host:         "localhost",
-			Port:         0,
+			Port:         70000,
 			ReadTimeout:  15 * time.Second,
 			WriteTimeout: 15 * time.Second,
 			IdleTimeout:  60 * time.Second,
@@ -104,6 +120,32 @@ func TestServerConfig_Validate(t *testing.T) {
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "server.read_timeout must be positive")
 	})
+
+	t.Run("read header timeout above read timeout fails", func(t *testing.T) {
+		cfg := config.ServerConfig{
+			Host:              "localhost",
+			Port:              8080,
+			ReadTimeout:       5 * time.Second,
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "server.read_header_timeout")
+		assert.Contains(t, err.Error(), "must be less than or equal to read_timeout")
+	})
+
+	t.Run("negative max header bytes fails", func(t *testing.T) {
+		cfg := config.ServerConfig{
+			Host:           "localhost",
+			Port:           8080,
+			MaxHeaderBytes: -1,
+		}
+
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "server.max_header_bytes must not be negative")
+	})
 }
 
 func TestServerConfig_Address(t *testing.T) {
@@ -118,3 +160,184 @@ func TestServerConfig_Address(t *testing.T) {
 	cfg.Port = 9000
 	assert.Equal(t, "0.0.0.0:9000", cfg.Address())
 }
+
+func TestServerConfig_NewHTTPServer(t *testing.T) {
+	cfg := config.ServerConfig{
+		Host:              "0.0.0.0",
+		Port:              9000,
+		ReadTimeout:       15 * time.Second,
+		ReadHeaderTimeout: 5 * time.Second,
+		WriteTimeout:      20 * time.Second,
+		IdleTimeout:       60 * time.Second,
+		MaxHeaderBytes:    65536,
+	}
+	handler := http.NewServeMux()
+
+	srv := cfg.NewHTTPServer(handler)
+
+	assert.Equal(t, "0.0.0.0:9000", srv.Addr)
+	assert.Same(t, handler, srv.Handler)
+	assert.Equal(t, 15*time.Second, srv.ReadTimeout)
+	assert.Equal(t, 5*time.Second, srv.ReadHeaderTimeout)
+	assert.Equal(t, 20*time.Second, srv.WriteTimeout)
+	assert.Equal(t, 60*time.Second, srv.IdleTimeout)
+	assert.Equal(t, 65536, srv.MaxHeaderBytes)
+}
+
+// serveLocal runs handler with cfg on an ephemeral loopback port. It returns
+// the bound address, reported through OnListen, and a channel receiving the
+// result of Run.
+func serveLocal(ctx context.Context, t *testing.T, cfg config.ServerConfig, handler http.Handler) (string, <-chan error) {
+	t.Helper()
+	cfg.Host, cfg.Port = "127.0.0.1", 0
+
+	bound := make(chan net.Addr, 1)
+	cfg.OnListen = func(addr net.Addr) { bound <- addr }
+
+	done := make(chan error, 1)
+	go func() { done <- cfg.Run(ctx, handler) }()
+
+	select {
+	case addr := <-bound:
+		require.NotZero(t, addr.(*net.TCPAddr).Port)
+		return addr.String(), done
+	case err := <-done:
+		require.FailNow(t, "Run returned before listening", "%v", err)
+		return "", nil
+	}
+}
+
+// testClient is an HTTP client that does not keep connections alive, so that
+// graceful shutdown in tests never waits on the client's idle connections
+var testClient = &http.Client{
+	Transport: &http.Transport{DisableKeepAlives: true},
+	Timeout:   5 * time.Second,
+}
+
+// waitForServer polls url until it responds or the test times out
+func waitForServer(t *testing.T, url string) {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		resp, err := testClient.Get(url)
+		if err != nil {
+			return false
+		}
+		resp.Body.Close()
+		return true
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestServerConfig_Run(t *testing.T) {
+	t.Run("serves until context is cancelled", func(t *testing.T) {
+		cfg := config.ServerConfig{
+			ShutdownTimeout: 5 * time.Second,
+		}
+		handler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = io.WriteString(w, "ok")
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		addr, done := serveLocal(ctx, t, cfg, handler)
+
+		url := "http://" + addr
+		waitForServer(t, url)
+
+		resp, err := testClient.Get(url)
+		require.NoError(t, err)
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		require.NoError(t, err)
+		assert.Equal(t, "ok", string(body))
+
+		cancel()
+		require.NoError(t, <-done)
+	})
+
+	t.Run("waits for in-flight requests during shutdown", func(t *testing.T) {
+		cfg := config.ServerConfig{
+			ShutdownTimeout: 5 * time.Second,
+		}
+		started := make(chan struct{})
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/slow" {
+				close(started)
+				time.Sleep(200 * time.Millisecond)
+			}
+			_, _ = io.WriteString(w, "done")
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		addr, done := serveLocal(ctx, t, cfg, handler)
+
+		url := "http://" + addr
+		waitForServer(t, url)
+
+		result := make(chan string, 1)
+		go func() {
+			resp, err := testClient.Get(url + "/slow")
+			if err != nil {
+				result <- err.Error()
+				return
+			}
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+			result <- string(body)
+		}()
+
+		<-started
+		cancel()
+
+		assert.Equal(t, "done", <-result)
+		require.NoError(t, <-done)
+	})
+
+	t.Run("returns error when shutdown timeout is exceeded", func(t *testing.T) {
+		cfg := config.ServerConfig{
+			ShutdownTimeout: 50 * time.Millisecond,
+		}
+		started := make(chan struct{})
+		release := make(chan struct{})
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/hang" {
+				close(started)
+				<-release
+			}
+		})
+		defer close(release)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		addr, done := serveLocal(ctx, t, cfg, handler)
+
+		url := "http://" + addr
+		waitForServer(t, url)
+
+		go func() {
+			resp, err := testClient.Get(url + "/hang")
+			if err == nil {
+				resp.Body.Close()
+			}
+		}()
+
+		<-started
+		cancel()
+
+		err := <-done
+		require.Error(t, err)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("returns listen errors", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer listener.Close()
+
+		cfg := config.ServerConfig{
+			Host: "127.0.0.1",
+			Port: listener.Addr().(*net.TCPAddr).Port,
+		}
+
+		err = cfg.Run(context.Background(), http.NotFoundHandler())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to listen on")
+	})
+}
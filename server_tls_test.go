@@ -0,0 +1,298 @@
+package config_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	config "github.com/JohnPlummer/jp-go-config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testCert is a generated certificate and key, written to PEM files
+type testCert struct {
+	cert     *x509.Certificate
+	key      *ecdsa.PrivateKey
+	certFile string
+	keyFile  string
+}
+
+// generateCert creates a certificate signed by parent, or self-signed when
+// parent is nil, and writes it to PEM files in dir
+func generateCert(t *testing.T, dir, name string, serial int64, parent *testCert) *testCert {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		DNSNames:     []string{"localhost"},
+	}
+
+	signer, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	tc := &testCert{
+		cert:     cert,
+		key:      key,
+		certFile: filepath.Join(dir, name+".crt"),
+		keyFile:  filepath.Join(dir, name+".key"),
+	}
+	require.NoError(t, os.WriteFile(tc.certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(tc.keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return tc
+}
+
+func TestServerConfig_TLSFromViper(t *testing.T) {
+	os.Setenv("SERVER_TLS_CERT_FILE", "/etc/tls/server.crt")
+	os.Setenv("SERVER_TLS_KEY_FILE", "/etc/tls/server.key")
+	os.Setenv("SERVER_TLS_CLIENT_CA_FILE", "/etc/tls/ca.crt")
+	os.Setenv("SERVER_TLS_MIN_VERSION", "1.3")
+	os.Setenv("SERVER_TLS_CIPHER_SUITES", "TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256, TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384")
+	os.Setenv("SERVER_TLS_CLIENT_AUTH", "require_and_verify")
+	defer func() {
+		os.Unsetenv("SERVER_TLS_CERT_FILE")
+		os.Unsetenv("SERVER_TLS_KEY_FILE")
+		os.Unsetenv("SERVER_TLS_CLIENT_CA_FILE")
+		os.Unsetenv("SERVER_TLS_MIN_VERSION")
+		os.Unsetenv("SERVER_TLS_CIPHER_SUITES")
+		os.Unsetenv("SERVER_TLS_CLIENT_AUTH")
+	}()
+
+	std, err := config.NewStandard()
+	require.NoError(t, err)
+
+	cfg := config.ServerConfigFromViper(std)
+
+	assert.True(t, cfg.TLS.Enabled())
+	assert.Equal(t, "/etc/tls/server.crt", cfg.TLS.CertFile)
+	assert.Equal(t, "/etc/tls/server.key", cfg.TLS.KeyFile)
+	assert.Equal(t, "/etc/tls/ca.crt", cfg.TLS.ClientCAFile)
+	assert.Equal(t, "1.3", cfg.TLS.MinVersion)
+	assert.Equal(t, []string{
+		"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256",
+		"TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384",
+	}, cfg.TLS.CipherSuites)
+	assert.Equal(t, "require_and_verify", cfg.TLS.ClientAuth)
+}
+
+func TestServerConfig_TLSValidate(t *testing.T) {
+	dir := t.TempDir()
+	ca := generateCert(t, dir, "ca", 1, nil)
+	server := generateCert(t, dir, "server", 2, ca)
+	other := generateCert(t, dir, "other", 3, ca)
+
+	validConfig := func() config.ServerConfig {
+		return config.ServerConfig{
+			Host: "localhost",
+			Port: 8443,
+			TLS: config.ServerTLSConfig{
+				CertFile:     server.certFile,
+				KeyFile:      server.keyFile,
+				ClientCAFile: ca.certFile,
+				MinVersion:   "1.2",
+				ClientAuth:   "require_and_verify",
+			},
+		}
+	}
+
+	t.Run("valid config passes", func(t *testing.T) {
+		cfg := validConfig()
+		require.NoError(t, cfg.Validate())
+	})
+
+	t.Run("missing key file fails", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.TLS.KeyFile = ""
+
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "server.tls.key_file is required")
+	})
+
+	t.Run("mismatched key fails", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.TLS.KeyFile = other.keyFile
+
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "must be a matching PEM certificate and key")
+	})
+
+	t.Run("non-PEM client CA fails", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.TLS.ClientCAFile = server.keyFile
+
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "server.tls.client_ca_file contains no PEM certificates")
+	})
+
+	t.Run("verifying client auth without CA fails", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.TLS.ClientCAFile = ""
+
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "server.tls.client_ca_file is required when client_auth is require_and_verify")
+	})
+
+	t.Run("invalid min version fails", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.TLS.MinVersion = "1.4"
+
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "server.tls.min_version must be one of")
+	})
+
+	t.Run("insecure cipher suite fails", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.TLS.CipherSuites = []string{"TLS_RSA_WITH_RC4_128_SHA"}
+
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "server.tls.cipher_suites contains insecure cipher suite")
+	})
+
+	t.Run("invalid client auth fails", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.TLS.ClientAuth = "always"
+
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "server.tls.client_auth must be one of")
+	})
+}
+
+func TestServerConfig_TLSConfig(t *testing.T) {
+	t.Run("fails when TLS is not enabled", func(t *testing.T) {
+		cfg := config.ServerConfig{Host: "localhost", Port: 8443}
+
+		_, err := cfg.TLSConfig()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "server.tls is not enabled")
+	})
+
+	t.Run("reloads certificate when files change", func(t *testing.T) {
+		dir := t.TempDir()
+		ca := generateCert(t, dir, "ca", 1, nil)
+		first := generateCert(t, dir, "server", 2, ca)
+
+		cfg := config.ServerConfig{
+			TLS: config.ServerTLSConfig{CertFile: first.certFile, KeyFile: first.keyFile},
+		}
+		tlsConfig, err := cfg.TLSConfig()
+		require.NoError(t, err)
+		assert.Equal(t, uint16(tls.VersionTLS12), tlsConfig.MinVersion)
+
+		current, err := tlsConfig.GetConfigForClient(&tls.ClientHelloInfo{})
+		require.NoError(t, err)
+		assert.Equal(t, first.cert.Raw, current.Certificates[0].Certificate[0])
+
+		// Overwrite the files with a new certificate and a distinct mtime
+		second := generateCert(t, dir, "server", 3, ca)
+		future := time.Now().Add(time.Minute)
+		require.NoError(t, os.Chtimes(second.certFile, future, future))
+		require.NoError(t, os.Chtimes(second.keyFile, future, future))
+
+		current, err = tlsConfig.GetConfigForClient(&tls.ClientHelloInfo{})
+		require.NoError(t, err)
+		assert.Equal(t, second.cert.Raw, current.Certificates[0].Certificate[0])
+
+		// A broken replacement keeps the last good certificate in use
+		require.NoError(t, os.WriteFile(second.certFile, []byte("not a certificate"), 0o600))
+		current, err = tlsConfig.GetConfigForClient(&tls.ClientHelloInfo{})
+		require.NoError(t, err)
+		assert.Equal(t, second.cert.Raw, current.Certificates[0].Certificate[0])
+	})
+}
+
+func TestServerConfig_RunMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := generateCert(t, dir, "ca", 1, nil)
+	server := generateCert(t, dir, "server", 2, ca)
+	client := generateCert(t, dir, "client", 3, ca)
+
+	cfg := config.ServerConfig{
+		Host:            "127.0.0.1",
+		ShutdownTimeout: 5 * time.Second,
+		TLS: config.ServerTLSConfig{
+			CertFile:     server.certFile,
+			KeyFile:      server.keyFile,
+			ClientCAFile: ca.certFile,
+			ClientAuth:   "require_and_verify",
+		},
+	}
+	require.NoError(t, cfg.Validate())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	addr, done := serveLocal(ctx, t, cfg, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.TLS.PeerCertificates[0].Subject.CommonName))
+	}))
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	clientCert, err := tls.LoadX509KeyPair(client.certFile, client.keyFile)
+	require.NoError(t, err)
+
+	newClient := func(certs ...tls.Certificate) *http.Client {
+		return &http.Client{
+			Timeout: 5 * time.Second,
+			Transport: &http.Transport{
+				DisableKeepAlives: true,
+				TLSClientConfig:   &tls.Config{RootCAs: roots, Certificates: certs, MinVersion: tls.VersionTLS12},
+			},
+		}
+	}
+
+	url := "https://" + addr
+	withCert := newClient(clientCert)
+	require.Eventually(t, func() bool {
+		resp, err := withCert.Get(url)
+		if err != nil {
+			return false
+		}
+		resp.Body.Close()
+		return resp.StatusCode == http.StatusOK
+	}, 5*time.Second, 10*time.Millisecond)
+
+	_, err = newClient().Get(url)
+	require.Error(t, err)
+
+	cancel()
+	require.NoError(t, <-done)
+}